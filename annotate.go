@@ -0,0 +1,150 @@
+package bin
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const annotateRowWidth = 16
+
+var _ io.Reader = (*annotatingReader)(nil)
+
+// annotatingReader records everything read through it, along with the named fields that were read, so an annotated dump can be produced.
+type annotatingReader struct {
+	reader io.Reader
+	data   []byte
+	depth  int
+	fields []*annotatedField
+}
+
+// annotatedField is a single named region of the input.
+type annotatedField struct {
+	name     string
+	depth    int
+	start    int
+	end      int
+	hasValue bool
+	value    any
+	parent   bool
+}
+
+func (a *annotatingReader) Read(p []byte) (int, error) {
+	n, err := a.reader.Read(p)
+	a.data = append(a.data, p[:n]...)
+	return n, err
+}
+
+// findAnnotator finds the annotatingReader for an Annotate call, looking through readers that are used internally to bound or count reads, or to carry a RecursionBudget.
+func findAnnotator(r io.Reader) *annotatingReader {
+	for {
+		switch v := r.(type) {
+		case *annotatingReader:
+			return v
+		case *countingReader:
+			r = v.reader
+		case *io.LimitedReader:
+			r = v.R
+		case *budgetReader:
+			r = v.reader
+		default:
+			return nil
+		}
+	}
+}
+
+func (a *annotatingReader) dump(w io.Writer) error {
+	for _, f := range a.fields {
+		indent := strings.Repeat("  ", f.depth)
+		if f.parent {
+			if _, err := fmt.Fprintf(w, "%08x  %s%s (%d bytes)\n", f.start, indent, f.name, f.end-f.start); err != nil {
+				return err
+			}
+			continue
+		}
+		line := fmt.Sprintf("%08x  %s%s:", f.start, indent, f.name)
+		data := a.data[f.start:f.end]
+		for i := 0; i == 0 || i < len(data); i += annotateRowWidth {
+			end := i + annotateRowWidth
+			if end > len(data) {
+				end = len(data)
+			}
+			if i > 0 {
+				line = fmt.Sprintf("%08x  %s%s ", f.start+i, indent, strings.Repeat(" ", len(f.name)))
+			}
+			row := fmt.Sprintf("% x", data[i:end])
+			if f.hasValue && end == len(data) {
+				row += fmt.Sprintf(" = %v", f.value)
+			}
+			if _, err := fmt.Fprintf(w, "%s %s\n", line, row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Annotate will write an annotated hex dump of everything read by m to w, which is helpful when reverse-engineering or verifying a format description.
+// Fields are labeled with Named or NamedValue, and each line includes the field's offset and bytes, along with its decoded value if known.
+// Named fields that contain other named fields are printed as a heading for their indented children.
+// The dump is written once m has finished reading, even if an error occurred, so the output shows how far the read got.
+// Writing is passed through to m as-is.
+func Annotate(m Mapper, w io.Writer) Mapper {
+	if m == nil || w == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			ar := &annotatingReader{reader: r}
+			err := m.Read(ar, endian)
+			if dumpErr := ar.dump(w); dumpErr != nil && err == nil {
+				err = dumpErr
+			}
+			return err
+		},
+		write: m.Write,
+	}
+}
+
+// Named labels a field with the given name for Annotate.
+// Outside of an Annotate read, this behaves exactly like m.
+func Named(name string, m Mapper) Mapper {
+	return named(name, m, nil, false)
+}
+
+// NamedValue labels a field with the given name for Annotate, and includes the value of target in the dump after the field is read.
+// Outside of an Annotate read, this behaves exactly like m.
+func NamedValue[T any](name string, target *T, m Mapper) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return named(name, m, func() any { return *target }, true)
+}
+
+func named(name string, m Mapper, value func() any, hasValue bool) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			ar := findAnnotator(r)
+			if ar == nil {
+				return m.Read(r, endian)
+			}
+			f := &annotatedField{name: name, depth: ar.depth, start: len(ar.data)}
+			ar.fields = append(ar.fields, f)
+			numFields := len(ar.fields)
+			ar.depth++
+			err := m.Read(r, endian)
+			ar.depth--
+			f.end = len(ar.data)
+			f.parent = len(ar.fields) > numFields
+			if err == nil && hasValue {
+				f.hasValue, f.value = true, value()
+			}
+			return err
+		},
+		write: m.Write,
+	}
+}
@@ -0,0 +1,51 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestAnnotate(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		out     strings.Builder
+		endian  = binary.BigEndian
+		version uint16
+		flags   uint8
+		payload []byte
+		length  uint8
+	)
+	m := Annotate(MapSequence(
+		Named("header", MapSequence(
+			Magic([]byte("BIN")),
+			NamedValue("version", &version, Int(&version)),
+			NamedValue("flags", &flags, Byte(&flags)),
+		)),
+		Named("payload", LenBytes(&payload, &length)),
+	), &out)
+	buf.Write([]byte{'B', 'I', 'N', 0, 2, 0x80, 18})
+	buf.Write(bytes.Repeat([]byte{0xAA}, 18))
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(2), version)
+	assert.Equal(t, `00000000  header (6 bytes)
+00000003    version: 00 02 = 2
+00000005    flags: 80 = 128
+00000006  payload: 12 aa aa aa aa aa aa aa aa aa aa aa aa aa aa aa
+00000016           aa aa aa
+`, out.String())
+
+	out.Reset()
+	buf.Reset()
+	buf.Write([]byte{'B', 'I', 'N', 0})
+	assert.Error(t, m.Read(&buf, endian))
+	assert.Equal(t, `00000000  header (4 bytes)
+00000003    version: 00
+`, out.String())
+
+	buf.Reset()
+	assert.NoError(t, Named("plain", Int(&version)).Write(&buf, endian))
+	assert.Equal(t, []byte{0, 2}, buf.Bytes())
+}
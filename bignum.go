@@ -0,0 +1,110 @@
+package bin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+var (
+	ErrInvalidSign     = errors.New("invalid sign byte")
+	ErrZeroDenominator = errors.New("denominator is zero")
+)
+
+const (
+	bigSignPositive byte = 0
+	bigSignNegative byte = 1
+)
+
+// BigInt maps a big.Int as a sign byte followed by its absolute value as big-endian bytes, prefixed with a uint32 length.
+// The sign byte is 0 for zero and positive values, and 1 for negative values.
+// Zero is written with a zero length and no magnitude bytes.
+func BigInt(target *big.Int) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				sign      byte
+				magnitude []byte
+				length    uint32
+			)
+			if err := MapSequence(Byte(&sign), LenBytes(&magnitude, &length)).Read(r, endian); err != nil {
+				return err
+			}
+			if sign != bigSignPositive && sign != bigSignNegative {
+				return fmt.Errorf("%w: %d", ErrInvalidSign, sign)
+			}
+			target.SetBytes(magnitude)
+			if sign == bigSignNegative {
+				target.Neg(target)
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			sign := bigSignPositive
+			if target.Sign() < 0 {
+				sign = bigSignNegative
+			}
+			magnitude := target.Bytes()
+			length, err := toSize[uint32](len(magnitude))
+			if err != nil {
+				return err
+			}
+			return MapSequence(Byte(&sign), LenBytes(&magnitude, &length)).Write(w, endian)
+		},
+	)
+}
+
+// BigRat maps a big.Rat as its numerator followed by its denominator, each mapped with BigInt.
+// A big.Rat is always kept in lowest terms, so the fraction is normalized on write.
+// Use BigFraction instead if the format requires an unreduced fraction to be preserved.
+// ErrZeroDenominator is returned on read if the denominator is zero.
+func BigRat(target *big.Rat) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var num, denom big.Int
+			if err := BigFraction(&num, &denom).Read(r, endian); err != nil {
+				return err
+			}
+			target.SetFrac(&num, &denom)
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			num, denom := new(big.Int).Set(target.Num()), new(big.Int).Set(target.Denom())
+			return BigFraction(num, denom).Write(w, endian)
+		},
+	)
+}
+
+// BigFraction maps a fraction as a numerator followed by a denominator, each mapped with BigInt.
+// Unlike BigRat, the fraction is preserved exactly as given, without being reduced to lowest terms.
+// ErrZeroDenominator is returned if the denominator is zero.
+func BigFraction(num, denom *big.Int) Mapper {
+	if num == nil || denom == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if err := MapSequence(BigInt(num), BigInt(denom)).Read(r, endian); err != nil {
+				return err
+			}
+			if denom.Sign() == 0 {
+				return ErrZeroDenominator
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			if denom.Sign() == 0 {
+				return ErrZeroDenominator
+			}
+			return MapSequence(BigInt(num), BigInt(denom)).Write(w, endian)
+		},
+	)
+}
@@ -0,0 +1,79 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestBigInt(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		val    = big.NewInt(-258)
+	)
+	m := BigInt(val)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{1, 0, 0, 0, 2, 1, 2}, buf.Bytes())
+	val.SetInt64(5)
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, int64(-258), val.Int64())
+
+	val.SetInt64(0)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0, 0, 0}, buf.Bytes())
+	val.SetInt64(5)
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, 0, val.Sign())
+
+	buf.Write([]byte{2, 0, 0, 0, 0})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidSign)
+}
+
+func TestBigRat(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+	)
+	for _, tc := range []string{"0", "-3/4", "6/8", "-123456789012345678901234567890/7", "5"} {
+		t.Run(tc, func(t *testing.T) {
+			expected, ok := new(big.Rat).SetString(tc)
+			assert.True(t, ok)
+			val := new(big.Rat).Set(expected)
+			m := BigRat(val)
+			assert.NoError(t, m.Write(&buf, endian))
+			val.SetInt64(99)
+			assert.NoError(t, m.Read(&buf, endian))
+			assert.Equal(t, 0, expected.Cmp(val), "Expected %s, but got %s", expected, val)
+		})
+	}
+
+	buf.Reset()
+	val := big.NewRat(6, 8)
+	assert.NoError(t, BigRat(val).Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0, 0, 1, 3, 0, 0, 0, 0, 1, 4}, buf.Bytes(), "The fraction should be normalized")
+
+	buf.Reset()
+	buf.Write([]byte{0, 0, 0, 0, 1, 3, 0, 0, 0, 0, 0})
+	assert.ErrorIs(t, BigRat(val).Read(&buf, endian), ErrZeroDenominator)
+}
+
+func TestBigFraction(t *testing.T) {
+	var (
+		buf        bytes.Buffer
+		endian     = binary.BigEndian
+		num, denom = big.NewInt(-6), big.NewInt(8)
+	)
+	m := BigFraction(num, denom)
+	assert.NoError(t, m.Write(&buf, endian))
+	num.SetInt64(0)
+	denom.SetInt64(1)
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, int64(-6), num.Int64(), "The fraction should not be reduced")
+	assert.Equal(t, int64(8), denom.Int64())
+
+	denom.SetInt64(0)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrZeroDenominator)
+}
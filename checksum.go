@@ -0,0 +1,205 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+var (
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+var _ io.Writer = (*internetHash)(nil)
+
+// internetHash incrementally computes the RFC 1071 one's complement checksum of the bytes written to it.
+type internetHash struct {
+	sum     uint64
+	odd     bool
+	pending byte
+}
+
+func (h *internetHash) Write(p []byte) (int, error) {
+	n := len(p)
+	if h.odd && len(p) > 0 {
+		h.sum += uint64(h.pending)<<8 | uint64(p[0])
+		h.odd = false
+		p = p[1:]
+	}
+	for i := 0; i+1 < len(p); i += 2 {
+		h.sum += uint64(p[i])<<8 | uint64(p[i+1])
+	}
+	if len(p)%2 == 1 {
+		h.odd, h.pending = true, p[len(p)-1]
+	}
+	// Each word adds less than 1<<16, so a 64-bit sum can't overflow within a single write of any practical size.
+	// Folding after each write keeps the carries from accumulating across writes.
+	for h.sum>>16 != 0 {
+		h.sum = h.sum&0xFFFF + h.sum>>16
+	}
+	return n, nil
+}
+
+// Sum16 returns the checksum of the bytes written so far.
+// An odd length is handled as if the data were padded with a trailing zero byte.
+func (h *internetHash) Sum16() uint16 {
+	sum := h.sum
+	if h.odd {
+		sum += uint64(h.pending) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xFFFF + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// internetChecksum computes the RFC 1071 one's complement checksum of data.
+// An odd length is handled as if data were padded with a trailing zero byte.
+func internetChecksum(data []byte) uint16 {
+	var h internetHash
+	_, _ = h.Write(data)
+	return h.Sum16()
+}
+
+// InternetChecksum maps m followed by the RFC 1071 internet checksum of the bytes mapped by m, as used in IP, TCP, and UDP.
+// The checksum is always mapped in network byte order (big-endian), regardless of the endian policy.
+// On read, the checksum is updated as bytes flow through m, so a region of any size is validated in constant memory.
+// On write, the output of m is buffered, so nothing is written if m fails.
+// On write, the computed checksum is set in stored before it's written.
+// On read, the checksum is read into stored, and ErrChecksumMismatch is returned if it doesn't match the computed checksum.
+func InternetChecksum(m Mapper, stored *uint16) Mapper {
+	if m == nil || stored == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var h internetHash
+			if err := m.Read(io.TeeReader(r, &h), endian); err != nil {
+				return err
+			}
+			if err := binary.Read(r, binary.BigEndian, stored); err != nil {
+				return err
+			}
+			if sum := h.Sum16(); sum != *stored {
+				return fmt.Errorf("%w: computed 0x%04X, but found 0x%04X", ErrChecksumMismatch, sum, *stored)
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var (
+				buf bytes.Buffer
+				h   internetHash
+			)
+			if err := m.Write(io.MultiWriter(&buf, &h), endian); err != nil {
+				return err
+			}
+			*stored = h.Sum16()
+			if _, err := buf.WriteTo(w); err != nil {
+				return err
+			}
+			return binary.Write(w, binary.BigEndian, stored)
+		},
+	)
+}
+
+// Checksum32 maps m followed by the CRC-32 (IEEE) checksum of the bytes mapped by m.
+// On read, the checksum is updated as bytes flow through m, so a region of any size is validated in constant memory.
+// On write, the output of m is buffered, so nothing is written if m fails.
+// On write, the computed checksum is set in stored before it's written.
+// On read, the checksum is read into stored, and ErrChecksumMismatch is returned if it doesn't match the computed checksum.
+//
+// Note that the checksum is only stable for a given value if m produces the same bytes each time, so SortedMap should be used instead of Map within m.
+func Checksum32(m Mapper, stored *uint32) Mapper {
+	if m == nil || stored == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			h := crc32.NewIEEE()
+			if err := m.Read(io.TeeReader(r, h), endian); err != nil {
+				return err
+			}
+			if err := binary.Read(r, endian, stored); err != nil {
+				return err
+			}
+			if sum := h.Sum32(); sum != *stored {
+				return fmt.Errorf("%w: computed 0x%08X, but found 0x%08X", ErrChecksumMismatch, sum, *stored)
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			h := crc32.NewIEEE()
+			if err := m.Write(io.MultiWriter(&buf, h), endian); err != nil {
+				return err
+			}
+			*stored = h.Sum32()
+			if _, err := buf.WriteTo(w); err != nil {
+				return err
+			}
+			return binary.Write(w, endian, stored)
+		},
+	)
+}
+
+// ScatteredChecksum accumulates a checksum over several regions that aren't contiguous, such as a header and body that are separated by fields that aren't covered by the checksum.
+// Regions are marked with ChecksumRegion, and the accumulated checksum is mapped with ChecksumResult.
+type ScatteredChecksum struct {
+	hash hash.Hash32
+}
+
+// NewScatteredChecksum creates a new ScatteredChecksum that uses the given hash, such as crc32.NewIEEE().
+func NewScatteredChecksum(h hash.Hash32) *ScatteredChecksum {
+	return &ScatteredChecksum{hash: h}
+}
+
+// Reset clears the accumulated checksum, which is needed if mapping failed before ChecksumResult was reached.
+func (s *ScatteredChecksum) Reset() {
+	s.hash.Reset()
+}
+
+// ChecksumRegion marks the bytes mapped by m as covered by the checksum.
+// Bytes mapped between regions are not included, and regions are accumulated in the order they're mapped.
+func ChecksumRegion(sum *ScatteredChecksum, m Mapper) Mapper {
+	if sum == nil || sum.hash == nil || m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			return m.Read(io.TeeReader(r, sum.hash), endian)
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			return m.Write(io.MultiWriter(w, sum.hash), endian)
+		},
+	)
+}
+
+// ChecksumResult maps the checksum accumulated by all regions that were mapped before it, and then resets the checksum so it can be used for the next record.
+// On write, the computed checksum is set in stored before it's written.
+// On read, the checksum is read into stored, and ErrChecksumMismatch is returned if it doesn't match the computed checksum.
+func ChecksumResult(sum *ScatteredChecksum, stored *uint32) Mapper {
+	if sum == nil || sum.hash == nil || stored == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			defer sum.hash.Reset()
+			if err := binary.Read(r, endian, stored); err != nil {
+				return err
+			}
+			if computed := sum.hash.Sum32(); computed != *stored {
+				return fmt.Errorf("%w: computed 0x%08X, but found 0x%08X", ErrChecksumMismatch, computed, *stored)
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			defer sum.hash.Reset()
+			*stored = sum.hash.Sum32()
+			return binary.Write(w, endian, stored)
+		},
+	)
+}
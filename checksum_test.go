@@ -0,0 +1,182 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"hash/crc32"
+	"io"
+	"testing"
+)
+
+func TestInternetChecksum(t *testing.T) {
+	// Example from RFC 1071 section 3
+	assert.Equal(t, ^uint16(0xDDF2), internetChecksum([]byte{0x00, 0x01, 0xF2, 0x03, 0xF4, 0xF5, 0xF6, 0xF7}))
+	assert.Equal(t, internetChecksum([]byte{0x12, 0x34, 0x56, 0x00}), internetChecksum([]byte{0x12, 0x34, 0x56}), "Odd length data should be zero padded")
+
+	var (
+		buf    bytes.Buffer
+		endian = binary.LittleEndian
+		data   = []byte{0x00, 0x01, 0xF2, 0x03, 0xF4, 0xF5, 0xF6, 0xF7}
+		sum    uint16
+	)
+	m := InternetChecksum(FixedBytes(&data, uint8(8)), &sum)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint16(0x220D), sum)
+	assert.Equal(t, []byte{0x22, 0x0D}, buf.Bytes()[8:])
+
+	data, sum = nil, 0
+	out := buf.Bytes()
+	assert.NoError(t, m.Read(bytes.NewReader(out), endian))
+	assert.Equal(t, uint16(0x220D), sum)
+
+	out[3] = 0xFF
+	assert.ErrorIs(t, m.Read(bytes.NewReader(out), endian), ErrChecksumMismatch)
+
+	buf.Reset()
+	m = InternetChecksum(MapSequence(FixedBytes(&data, uint8(8)), ReadOnly(Byte(new(byte)).Read)), &sum)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrWriteNotSupported)
+	assert.Zero(t, buf.Len(), "Nothing should be written if m fails")
+}
+
+func TestChecksum32(t *testing.T) {
+	var (
+		endian = binary.BigEndian
+		sum    uint32
+		data   = map[string]uint16{}
+	)
+	for i := 0; i < 50; i++ {
+		data[string(rune('A'+i))] = uint16(i)
+	}
+	m := Checksum32(SortedMap(&data, func(a, b string) bool { return a < b }, NullTermString, Int[uint16]), &sum)
+
+	var first []byte
+	for i := 0; i < 10; i++ {
+		var buf bytes.Buffer
+		assert.NoError(t, m.Write(&buf, endian))
+		if first == nil {
+			first = buf.Bytes()
+			continue
+		}
+		assert.Equal(t, first, buf.Bytes(), "Output and checksum should be stable regardless of map iteration order")
+	}
+	expected := sum
+
+	data, sum = nil, 0
+	assert.NoError(t, m.Read(bytes.NewReader(first), endian))
+	assert.Equal(t, expected, sum)
+	assert.Len(t, data, 50)
+
+	first[5] ^= 0xFF
+	assert.ErrorIs(t, m.Read(bytes.NewReader(first), endian), ErrChecksumMismatch)
+
+	var buf bytes.Buffer
+	m = Checksum32(MapSequence(NullTermString(new(string)), ReadOnly(Byte(new(byte)).Read)), &sum)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrWriteNotSupported)
+	assert.Zero(t, buf.Len(), "Nothing should be written if m fails")
+}
+
+func TestInternetChecksum_Streaming(t *testing.T) {
+	data := []byte{0x00, 0x01, 0xF2, 0x03, 0xF4, 0xF5, 0xF6, 0xF7, 0x12}
+	for split := 0; split <= len(data); split++ {
+		var h internetHash
+		_, _ = h.Write(data[:split])
+		_, _ = h.Write(data[split:])
+		assert.Equal(t, internetChecksum(data), h.Sum16(), "Split at %d should match", split)
+	}
+}
+
+// referenceInternetChecksum is a direct implementation of the RFC 1071 algorithm, which folds the carry after every word.
+func referenceInternetChecksum(data []byte) uint16 {
+	var sum uint32
+	for i := 0; i < len(data); i += 2 {
+		word := uint32(data[i]) << 8
+		if i+1 < len(data) {
+			word |= uint32(data[i+1])
+		}
+		sum += word
+		sum = sum&0xFFFF + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+func TestInternetChecksum_LargeWrite(t *testing.T) {
+	data := bytes.Repeat([]byte{0xFF}, 1<<20)
+	data[0] = 0xFE
+	assert.Equal(t, uint16(0x0100), referenceInternetChecksum(data))
+	assert.Equal(t, referenceInternetChecksum(data), internetChecksum(data), "Carries shouldn't be lost in a single large write")
+
+	var h internetHash
+	_, _ = h.Write(data)
+	_, _ = h.Write(data[1:])
+	assert.Equal(t, referenceInternetChecksum(append(append([]byte{}, data...), data[1:]...)), h.Sum16())
+}
+
+func BenchmarkChecksum32_LargeRegion(b *testing.B) {
+	const size = 64 << 20
+	var (
+		endian = binary.BigEndian
+		sum    uint32
+		m      = Checksum32(ConstantFill(size, 0), &sum)
+	)
+	assert.NoError(b, m.Write(io.Discard, endian))
+	trailer := make([]byte, 4)
+	endian.PutUint32(trailer, sum)
+
+	b.ReportAllocs()
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := io.MultiReader(io.LimitReader(zeroReader{}, size), bytes.NewReader(trailer))
+		if err := m.Read(r, endian); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// zeroReader produces an endless stream of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestScatteredChecksum(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		endian   = binary.BigEndian
+		sum      = NewScatteredChecksum(crc32.NewIEEE())
+		header   = uint32(0xDEADBEEF)
+		reserved = uint16(0xFFFF)
+		body     = "body"
+		stored   uint32
+	)
+	m := MapSequence(
+		ChecksumRegion(sum, Int(&header)),
+		Int(&reserved),
+		ChecksumRegion(sum, NullTermString(&body)),
+		ChecksumResult(sum, &stored),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, crc32.ChecksumIEEE([]byte("\xDE\xAD\xBE\xEFbody\x00")), stored, "Unmarked fields should not be included")
+
+	header, reserved, body, stored = 0, 0, "", 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint32(0xDEADBEEF), header)
+	assert.Equal(t, "body", body)
+
+	assert.NoError(t, m.Write(&buf, endian), "The checksum should be reset for the next record")
+	data := buf.Bytes()
+	data[4] ^= 0xFF
+	assert.NoError(t, m.Read(bytes.NewReader(data), endian), "Changes outside of the regions should not be detected")
+	data[0] ^= 0xFF
+	assert.ErrorIs(t, m.Read(bytes.NewReader(data), endian), ErrChecksumMismatch)
+
+	assert.Error(t, m.Read(bytes.NewReader(data[:8]), endian))
+	sum.Reset()
+	data[0] ^= 0xFF
+	assert.NoError(t, m.Read(bytes.NewReader(data), endian))
+}
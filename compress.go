@@ -0,0 +1,266 @@
+package bin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"github.com/golang/snappy"
+	"io"
+)
+
+// Codec is used to transform data as it's read and written, such as with compression.
+type Codec interface {
+	// NewReader wraps the given io.Reader to decode data as it's read.
+	NewReader(r io.Reader) (io.Reader, error)
+	// NewWriter wraps the given io.Writer to encode data as it's written.
+	// The returned io.WriteCloser will be closed to flush any remaining data once the mapped data is written.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+var (
+	GzipCodec   Codec = gzipCodec{}
+	ZlibCodec   Codec = zlibCodec{}
+	SnappyCodec Codec = snappyCodec{}
+	// CapnpPackedCodec uses the Cap'n Proto packing scheme, which compresses zero bytes in each 8-byte word using a tag byte that indicates which bytes are non-zero.
+	CapnpPackedCodec Codec = capnpPackedCodec{}
+)
+
+type gzipCodec struct{}
+
+func (gzipCodec) NewReader(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+type zlibCodec struct{}
+
+func (zlibCodec) NewReader(r io.Reader) (io.Reader, error) {
+	return zlib.NewReader(r)
+}
+
+func (zlibCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriter(w), nil
+}
+
+type snappyCodec struct{}
+
+func (snappyCodec) NewReader(r io.Reader) (io.Reader, error) {
+	return snappy.NewReader(r), nil
+}
+
+func (snappyCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return snappy.NewBufferedWriter(w), nil
+}
+
+const capnpWordSize = 8
+
+type capnpPackedCodec struct{}
+
+// NewReader unpacks the whole input on the first read, since the packed region is bounded by Coded.
+func (capnpPackedCodec) NewReader(r io.Reader) (io.Reader, error) {
+	return &capnpPackedReader{reader: r}, nil
+}
+
+func (capnpPackedCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return &capnpPackedWriter{writer: w}, nil
+}
+
+type capnpPackedReader struct {
+	reader   io.Reader
+	unpacked *bytes.Reader
+}
+
+func (c *capnpPackedReader) Read(p []byte) (int, error) {
+	if c.unpacked == nil {
+		packed, err := io.ReadAll(c.reader)
+		if err != nil {
+			return 0, err
+		}
+		unpacked, err := capnpUnpack(packed)
+		if err != nil {
+			return 0, err
+		}
+		c.unpacked = bytes.NewReader(unpacked)
+	}
+	return c.unpacked.Read(p)
+}
+
+type capnpPackedWriter struct {
+	writer io.Writer
+	buf    bytes.Buffer
+}
+
+func (c *capnpPackedWriter) Write(p []byte) (int, error) {
+	return c.buf.Write(p)
+}
+
+// Close pads the written data with zeros to a whole number of words, and writes the packed data.
+func (c *capnpPackedWriter) Close() error {
+	if rem := c.buf.Len() % capnpWordSize; rem != 0 {
+		c.buf.Write(make([]byte, capnpWordSize-rem))
+	}
+	_, err := c.writer.Write(capnpPack(c.buf.Bytes()))
+	return err
+}
+
+// capnpPack packs src, which must be a whole number of words.
+// A word with a zero tag is followed by a count of additional zero words, and a word with a 0xFF tag is followed by a count of words that are copied as-is.
+// Words are copied as-is while they have no more than one zero byte, since that's where packing stops saving space.
+func capnpPack(src []byte) []byte {
+	var (
+		out      []byte
+		numWords = len(src) / capnpWordSize
+		word     = func(i int) []byte {
+			return src[i*capnpWordSize : (i+1)*capnpWordSize]
+		}
+		zeros = func(w []byte) int {
+			return bytes.Count(w, []byte{0})
+		}
+	)
+	for i := 0; i < numWords; i++ {
+		w := word(i)
+		var tag byte
+		tagPos := len(out)
+		out = append(out, 0)
+		for j, b := range w {
+			if b != 0 {
+				tag |= 1 << j
+				out = append(out, b)
+			}
+		}
+		out[tagPos] = tag
+		switch tag {
+		case 0x00:
+			var run byte
+			for i+1 < numWords && run < 0xFF && zeros(word(i+1)) == capnpWordSize {
+				run++
+				i++
+			}
+			out = append(out, run)
+		case 0xFF:
+			var run byte
+			start := i + 1
+			for i+1 < numWords && run < 0xFF && zeros(word(i+1)) <= 1 {
+				run++
+				i++
+			}
+			out = append(out, run)
+			out = append(out, src[start*capnpWordSize:(i+1)*capnpWordSize]...)
+		}
+	}
+	return out
+}
+
+// capnpUnpack reverses capnpPack, returning ErrInvalidFrame if the packed data is truncated.
+func capnpUnpack(src []byte) ([]byte, error) {
+	var (
+		out       []byte
+		truncated = fmt.Errorf("%w: truncated packed data", ErrInvalidFrame)
+	)
+	for i := 0; i < len(src); {
+		tag := src[i]
+		i++
+		for j := 0; j < capnpWordSize; j++ {
+			if tag&(1<<j) == 0 {
+				out = append(out, 0)
+				continue
+			}
+			if i >= len(src) {
+				return nil, truncated
+			}
+			out = append(out, src[i])
+			i++
+		}
+		if tag != 0x00 && tag != 0xFF {
+			continue
+		}
+		if i >= len(src) {
+			return nil, truncated
+		}
+		run := int(src[i]) * capnpWordSize
+		i++
+		if tag == 0x00 {
+			out = append(out, make([]byte, run)...)
+			continue
+		}
+		if i+run > len(src) {
+			return nil, truncated
+		}
+		out = append(out, src[i:i+run]...)
+		i += run
+	}
+	return out, nil
+}
+
+// Coded maps m through a region encoded with the given Codec, which is prefixed with its encoded size in bytes as a uint32.
+// The size prefix allows the encoded region to be embedded in a larger stream without over-reading.
+func Coded(codec Codec, m Mapper) Mapper {
+	if codec == nil || m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var length uint32
+			if err := Size(&length).Read(r, endian); err != nil {
+				return err
+			}
+			lr := io.LimitReader(r, int64(length))
+			cr, err := codec.NewReader(lr)
+			if err != nil {
+				return err
+			}
+			// Decoders commonly return their final bytes along with io.EOF, so the region is decoded up front.
+			decoded, err := io.ReadAll(cr)
+			if err != nil {
+				return err
+			}
+			if err := m.Read(bytes.NewReader(decoded), endian); err != nil {
+				return err
+			}
+			_, err = io.Copy(io.Discard, lr)
+			return err
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			cw, err := codec.NewWriter(&buf)
+			if err != nil {
+				return err
+			}
+			if err := m.Write(cw, endian); err != nil {
+				_ = cw.Close()
+				return err
+			}
+			if err := cw.Close(); err != nil {
+				return err
+			}
+			length, err := toSize[uint32](buf.Len())
+			if err != nil {
+				return err
+			}
+			if err := Size(&length).Write(w, endian); err != nil {
+				return err
+			}
+			_, err = buf.WriteTo(w)
+			return err
+		},
+	)
+}
+
+// Snappy will compress the output of m using the Snappy framing format, and decompress it before reading with m.
+// This is the same as using Coded with SnappyCodec.
+func Snappy(m Mapper) Mapper {
+	return Coded(SnappyCodec, m)
+}
+
+// CapnpPacked will pack the output of m using the Cap'n Proto packing scheme, and unpack it before reading with m.
+// This is cheap and effective for data with many zero bytes, such as structs with many zero fields.
+// The output of m is padded with zeros to a multiple of 8 bytes before packing.
+// This is the same as using Coded with CapnpPackedCodec.
+func CapnpPacked(m Mapper) Mapper {
+	return Coded(CapnpPackedCodec, m)
+}
@@ -0,0 +1,169 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSnappy(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		endian  = binary.BigEndian
+		text    = strings.Repeat("compress me! ", 100)
+		trailer = uint16(0xABCD)
+	)
+	m := MapSequence(
+		Snappy(NullTermString(&text)),
+		Int(&trailer),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Less(t, buf.Len(), len(text))
+
+	text, trailer = "", 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, strings.Repeat("compress me! ", 100), text)
+	assert.Equal(t, uint16(0xABCD), trailer)
+}
+
+type xorCodec byte
+
+func (c xorCodec) NewReader(r io.Reader) (io.Reader, error) {
+	return &xorReader{r: r, key: byte(c)}, nil
+}
+
+func (c xorCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return &xorWriter{w: w, key: byte(c)}, nil
+}
+
+type xorReader struct {
+	r   io.Reader
+	key byte
+}
+
+func (x *xorReader) Read(p []byte) (int, error) {
+	n, err := x.r.Read(p)
+	for i := 0; i < n; i++ {
+		p[i] ^= x.key
+	}
+	return n, err
+}
+
+type xorWriter struct {
+	w   io.Writer
+	key byte
+}
+
+func (x *xorWriter) Write(p []byte) (int, error) {
+	out := make([]byte, len(p))
+	for i := range p {
+		out[i] = p[i] ^ x.key
+	}
+	return x.w.Write(out)
+}
+
+func (x *xorWriter) Close() error {
+	return nil
+}
+
+func TestCoded(t *testing.T) {
+	for name, codec := range map[string]Codec{
+		"gzip":   GzipCodec,
+		"zlib":   ZlibCodec,
+		"snappy": SnappyCodec,
+		"capnp":  CapnpPackedCodec,
+		"custom": xorCodec(0x5A),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				buf     bytes.Buffer
+				endian  = binary.BigEndian
+				text    = strings.Repeat("encode me! ", 50)
+				trailer = uint16(0xABCD)
+			)
+			m := MapSequence(
+				Coded(codec, NullTermString(&text)),
+				Int(&trailer),
+			)
+			assert.NoError(t, m.Write(&buf, endian))
+
+			text, trailer = "", 0
+			assert.NoError(t, m.Read(&buf, endian))
+			assert.Equal(t, strings.Repeat("encode me! ", 50), text)
+			assert.Equal(t, uint16(0xABCD), trailer)
+		})
+	}
+}
+
+func TestCapnpPack(t *testing.T) {
+	tests := map[string]struct {
+		unpacked, packed []byte
+	}{
+		"Empty":          {[]byte{}, nil},
+		"Zero word":      {make([]byte, 8), []byte{0, 0}},
+		"Sparse word":    {[]byte{0, 0, 12, 0, 0, 34, 0, 0}, []byte{0x24, 12, 34}},
+		"Full word":      {[]byte{1, 3, 2, 4, 5, 7, 6, 8}, []byte{0xFF, 1, 3, 2, 4, 5, 7, 6, 8, 0}},
+		"Zero run":       {make([]byte, 24), []byte{0, 2}},
+		"Zero then full": {[]byte{0, 0, 0, 0, 0, 0, 0, 0, 1, 3, 2, 4, 5, 7, 6, 8}, []byte{0, 0, 0xFF, 1, 3, 2, 4, 5, 7, 6, 8, 0}},
+		"Full run":       {[]byte{1, 3, 2, 4, 5, 7, 6, 8, 8, 6, 7, 4, 5, 2, 3, 1}, []byte{0xFF, 1, 3, 2, 4, 5, 7, 6, 8, 1, 8, 6, 7, 4, 5, 2, 3, 1}},
+		"Run with single zero bytes": {
+			[]byte{
+				1, 2, 3, 4, 5, 6, 7, 8,
+				1, 2, 3, 4, 5, 6, 7, 8,
+				1, 2, 3, 4, 5, 6, 7, 8,
+				1, 2, 3, 4, 5, 6, 7, 8,
+				0, 2, 4, 0, 9, 0, 5, 1,
+			},
+			[]byte{
+				0xFF, 1, 2, 3, 4, 5, 6, 7, 8,
+				3,
+				1, 2, 3, 4, 5, 6, 7, 8,
+				1, 2, 3, 4, 5, 6, 7, 8,
+				1, 2, 3, 4, 5, 6, 7, 8,
+				0xD6, 2, 4, 9, 5, 1,
+			},
+		},
+		"Mixed": {
+			[]byte{
+				8, 0, 100, 6, 0, 1, 1, 2,
+				0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 1, 0, 2, 0, 3, 1,
+			},
+			[]byte{0xED, 8, 100, 6, 1, 1, 2, 0, 1, 0xD4, 1, 2, 3, 1},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.packed, capnpPack(tc.unpacked))
+			unpacked, err := capnpUnpack(tc.packed)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.unpacked, append([]byte{}, unpacked...))
+		})
+	}
+
+	_, err := capnpUnpack([]byte{0xFF, 1, 2, 3})
+	assert.ErrorIs(t, err, ErrInvalidFrame)
+	_, err = capnpUnpack([]byte{0xFF, 1, 2, 3, 4, 5, 6, 7, 8, 2, 1})
+	assert.ErrorIs(t, err, ErrInvalidFrame)
+}
+
+func TestCapnpPacked(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.LittleEndian
+		vals   = []uint64{1, 0, 0, 0, 1 << 40}
+		after  = uint8(7)
+	)
+	m := MapSequence(CapnpPacked(Slice(&vals, uint8(5), Int[uint64])), Int(&after))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{6, 0, 0, 0, 0x01, 1, 0, 2, 0x20, 1, 7}, buf.Bytes())
+
+	vals, after = nil, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []uint64{1, 0, 0, 0, 1 << 40}, vals)
+	assert.Equal(t, uint8(7), after)
+}
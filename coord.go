@@ -0,0 +1,213 @@
+package bin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	ErrCoordRange   = errors.New("coordinate is out of range")
+	ErrInvalidScale = errors.New("invalid scale factor")
+)
+
+// CoordScaleE7 scales degrees to units of 1e-7 degrees, as is used in OpenStreetMap PBF files.
+// This gives about 1cm of precision, and a full range longitude fits in an int32.
+const CoordScaleE7 = 1e7
+
+// ScaledCoord maps a coordinate as round(value * scale) stored as a signed integer that is width bytes wide, where width is 1, 2, 4, or 8.
+// On read, the integer is divided by scale.
+// ErrOverflow is returned on write if the scaled value doesn't fit in the integer.
+// ErrInvalidScale is returned if scale is zero, NaN, or infinite, and ErrInvalidWidth is returned if width is not one of the listed sizes.
+func ScaledCoord(target *float64, scale float64, width int) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	if scale == 0 || math.IsNaN(scale) || math.IsInf(scale, 0) {
+		return errMapping(fmt.Errorf("%w: %v", ErrInvalidScale, scale))
+	}
+	switch width {
+	case 1, 2, 4, 8:
+	default:
+		return errMapping(fmt.Errorf("%w: coordinate width %d", ErrInvalidWidth, width))
+	}
+	limit := math.Ldexp(1, width*8-1)
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var raw int64
+			switch width {
+			case 1:
+				var v int8
+				if err := Int(&v).Read(r, endian); err != nil {
+					return err
+				}
+				raw = int64(v)
+			case 2:
+				var v int16
+				if err := Int(&v).Read(r, endian); err != nil {
+					return err
+				}
+				raw = int64(v)
+			case 4:
+				var v int32
+				if err := Int(&v).Read(r, endian); err != nil {
+					return err
+				}
+				raw = int64(v)
+			default:
+				if err := Int(&raw).Read(r, endian); err != nil {
+					return err
+				}
+			}
+			*target = float64(raw) / scale
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			scaled := math.Round(*target * scale)
+			if math.IsNaN(scaled) || scaled < -limit || scaled >= limit {
+				return fmt.Errorf("%w: %v scaled by %v doesn't fit in %d bytes", ErrOverflow, *target, scale, width)
+			}
+			raw := int64(scaled)
+			switch width {
+			case 1:
+				v := int8(raw)
+				return Int(&v).Write(w, endian)
+			case 2:
+				v := int16(raw)
+				return Int(&v).Write(w, endian)
+			case 4:
+				v := int32(raw)
+				return Int(&v).Write(w, endian)
+			default:
+				return Int(&raw).Write(w, endian)
+			}
+		},
+	)
+}
+
+// Latitude maps a latitude in degrees with ScaledCoord, using CoordScaleE7 and an int32.
+// ErrCoordRange is returned if the latitude is not within -90 through 90 degrees.
+func Latitude(target *float64) Mapper {
+	return boundedCoord(target, 90)
+}
+
+// Longitude maps a longitude in degrees with ScaledCoord, using CoordScaleE7 and an int32.
+// ErrCoordRange is returned if the longitude is not within -180 through 180 degrees.
+func Longitude(target *float64) Mapper {
+	return boundedCoord(target, 180)
+}
+
+func boundedCoord(target *float64, limit float64) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	check := func() error {
+		if !(*target >= -limit && *target <= limit) {
+			return fmt.Errorf("%w: %v is not within ±%v degrees", ErrCoordRange, *target, limit)
+		}
+		return nil
+	}
+	m := ScaledCoord(target, CoordScaleE7, 4)
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if err := m.Read(r, endian); err != nil {
+				return err
+			}
+			return check()
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			if err := check(); err != nil {
+				return err
+			}
+			return m.Write(w, endian)
+		},
+	)
+}
+
+// ScaledBy maps a physical value that is stored as a raw integer sample, where the scale and offset are declared by earlier header fields, such as GeoTIFF's ModelPixelScale.
+// The raw integer is mapped with the Mapper returned from rawMapper, and scale and offset are dereferenced each time a value is mapped, so they may be read earlier in the same sequence.
+// On read, target is set to raw * scale + offset.
+// On write, raw is computed as round((target - offset) / scale), and ErrOverflow is returned if it doesn't fit in an int32.
+// ErrInvalidScale is returned if scale is zero, NaN, or infinite when the value is mapped.
+func ScaledBy(scale, offset *float64, target *float64, rawMapper func(*int32) Mapper) Mapper {
+	if scale == nil || offset == nil || target == nil || rawMapper == nil {
+		return nilMapping
+	}
+	checkScale := func() error {
+		if *scale == 0 || math.IsNaN(*scale) || math.IsInf(*scale, 0) {
+			return fmt.Errorf("%w: %v", ErrInvalidScale, *scale)
+		}
+		return nil
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if err := checkScale(); err != nil {
+				return err
+			}
+			var raw int32
+			if err := rawMapper(&raw).Read(r, endian); err != nil {
+				return err
+			}
+			*target = float64(raw)*(*scale) + *offset
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			if err := checkScale(); err != nil {
+				return err
+			}
+			scaled := math.Round((*target - *offset) / *scale)
+			if math.IsNaN(scaled) || scaled < math.MinInt32 || scaled > math.MaxInt32 {
+				return fmt.Errorf("%w: %v with scale %v and offset %v doesn't fit in an int32", ErrOverflow, *target, *scale, *offset)
+			}
+			raw := int32(scaled)
+			return rawMapper(&raw).Write(w, endian)
+		},
+	)
+}
+
+// spreadBits spreads the bits of v so there's a zero bit between each of them.
+func spreadBits(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | x<<16) & 0x0000FFFF0000FFFF
+	x = (x | x<<8) & 0x00FF00FF00FF00FF
+	x = (x | x<<4) & 0x0F0F0F0F0F0F0F0F
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
+
+// compactBits reverses spreadBits, taking every other bit of x starting with the least significant bit.
+func compactBits(x uint64) uint32 {
+	x &= 0x5555555555555555
+	x = (x | x>>1) & 0x3333333333333333
+	x = (x | x>>2) & 0x0F0F0F0F0F0F0F0F
+	x = (x | x>>4) & 0x00FF00FF00FF00FF
+	x = (x | x>>8) & 0x0000FFFF0000FFFF
+	x = (x | x>>16) & 0x00000000FFFFFFFF
+	return uint32(x)
+}
+
+// MortonCode maps a coordinate pair as a uint64 Morton (Z-order) code, as is used in quadtree and spatial index formats.
+// The bits of x are stored in the even bits of the code, starting with the least significant bit, and the bits of y are stored in the odd bits.
+// On read, the code is read into target and de-interleaved into x and y.
+// On write, x and y are interleaved into target before it's written.
+func MortonCode(x, y *uint32, target *uint64) Mapper {
+	if x == nil || y == nil || target == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Int(target).Read(r, endian); err != nil {
+				return err
+			}
+			*x, *y = compactBits(*target), compactBits(*target>>1)
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			*target = spreadBits(*x) | spreadBits(*y)<<1
+			return Int(target).Write(w, endian)
+		},
+	)
+}
@@ -0,0 +1,128 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScaledCoord(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		val    = -122.4194155
+	)
+	m := ScaledCoord(&val, CoordScaleE7, 4)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0xB7, 0x08, 0x47, 0x95}, buf.Bytes())
+	val = 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.InDelta(t, -122.4194155, val, 1e-9)
+
+	val = 1.26
+	m = ScaledCoord(&val, 100, 1)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{126}, buf.Bytes())
+	val = 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, 1.26, val)
+
+	val = 1.28
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrOverflow)
+	val = -1.28
+	assert.NoError(t, m.Write(&buf, endian), "The minimum value should fit")
+
+	assert.ErrorIs(t, ScaledCoord(&val, 100, 3).Write(&buf, endian), ErrInvalidWidth)
+	assert.ErrorIs(t, ScaledCoord(&val, 0, 4).Write(&buf, endian), ErrInvalidScale)
+}
+
+func TestLatitudeLongitude(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		endian   = binary.LittleEndian
+		lat, lon = 37.7749295, -180.0
+	)
+	m := MapSequence(Latitude(&lat), Longitude(&lon))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, 8, buf.Len())
+	lat, lon = 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.InDelta(t, 37.7749295, lat, 1e-9)
+	assert.Equal(t, -180.0, lon)
+
+	lat = 90.5
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrCoordRange)
+	buf.Reset()
+	raw := int32(-1_900_000_000)
+	assert.NoError(t, Int(&raw).Write(&buf, endian))
+	assert.ErrorIs(t, Longitude(&lon).Read(&buf, endian), ErrCoordRange)
+}
+
+func TestScaledBy(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		endian  = binary.BigEndian
+		scale   = 0.5
+		offset  = -10.0
+		samples = []float64{-10, 0, 2.25, 100}
+	)
+	mapSample := func(v *float64) Mapper {
+		return ScaledBy(&scale, &offset, v, Int[int32])
+	}
+	m := MapSequence(
+		Float(&scale),
+		Float(&offset),
+		Slice(&samples, uint8(4), mapSample),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 20, 0, 0, 0, 25, 0, 0, 0, 220}, buf.Bytes()[16:], "Values should be rounded")
+
+	scale, offset, samples = 0, 0, nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, 0.5, scale)
+	assert.Equal(t, []float64{-10, 0, 2.5, 100}, samples)
+
+	var val = 1e10
+	assert.ErrorIs(t, ScaledBy(&scale, &offset, &val, Int[int32]).Write(&buf, endian), ErrOverflow)
+	scale = 0
+	assert.ErrorIs(t, ScaledBy(&scale, &offset, &val, Int[int32]).Read(&buf, endian), ErrInvalidScale)
+}
+
+func TestMortonCode(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		x, y   uint32
+		code   uint64
+	)
+	tests := map[[2]uint32]uint64{
+		{0, 0}:                   0,
+		{1, 0}:                   1,
+		{0, 1}:                   2,
+		{3, 5}:                   0b100111,
+		{0xFFFFFFFF, 0}:          0x5555555555555555,
+		{0, 0xFFFFFFFF}:          0xAAAAAAAAAAAAAAAA,
+		{0xFFFFFFFF, 0xFFFFFFFF}: 0xFFFFFFFFFFFFFFFF,
+	}
+	m := MortonCode(&x, &y, &code)
+	for coords, expected := range tests {
+		buf.Reset()
+		x, y = coords[0], coords[1]
+		assert.NoError(t, m.Write(&buf, endian))
+		assert.Equal(t, expected, code, "Coordinates %v", coords)
+		assert.Equal(t, expected, binary.BigEndian.Uint64(buf.Bytes()))
+
+		x, y, code = 0, 0, 0
+		assert.NoError(t, m.Read(&buf, endian))
+		assert.Equal(t, coords, [2]uint32{x, y})
+	}
+
+	for i := uint32(0); i < 1000; i++ {
+		x, y = i*2654435761, i*40503
+		buf.Reset()
+		assert.NoError(t, m.Write(&buf, endian))
+		assert.NoError(t, m.Read(&buf, endian))
+		assert.Equal(t, [2]uint32{i * 2654435761, i * 40503}, [2]uint32{x, y})
+	}
+}
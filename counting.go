@@ -0,0 +1,230 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+var (
+	ErrSizeMismatch       = errors.New("unexpected number of bytes mapped")
+	ErrInvalidGranularity = errors.New("progress granularity must be at least 1 byte")
+)
+
+var _ io.Reader = (*countingReader)(nil)
+
+// countingReader tracks the number of bytes read from the underlying reader.
+// If progress is set, it will be called with the count after each read that changes it.
+type countingReader struct {
+	reader   io.Reader
+	n        int64
+	progress func(n int64)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.n += int64(n)
+	if n > 0 && c.progress != nil {
+		c.progress(c.n)
+	}
+	return n, err
+}
+
+var _ io.Writer = (*countingWriter)(nil)
+
+// countingWriter tracks the number of bytes written to the underlying writer.
+// If progress is set, it will be called with the count after each write that changes it.
+type countingWriter struct {
+	writer   io.Writer
+	n        int64
+	progress func(n int64)
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.writer.Write(p)
+	c.n += int64(n)
+	if n > 0 && c.progress != nil {
+		c.progress(c.n)
+	}
+	return n, err
+}
+
+// ExactSize asserts that the given Mapper reads or writes exactly the expected number of bytes.
+// This is helpful for catching mistakes in a format description where a sub-structure is known to be a fixed size.
+// ErrSizeMismatch will be returned if the number of bytes read or written doesn't match expected.
+func ExactSize(m Mapper, expected int64) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			cr := &countingReader{reader: r}
+			if err := m.Read(cr, endian); err != nil {
+				return err
+			}
+			if cr.n != expected {
+				return fmt.Errorf("%w: expected to read %d bytes, but read %d", ErrSizeMismatch, expected, cr.n)
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			cw := &countingWriter{writer: w}
+			if err := m.Write(cw, endian); err != nil {
+				return err
+			}
+			if cw.n != expected {
+				return fmt.Errorf("%w: expected to write %d bytes, but wrote %d", ErrSizeMismatch, expected, cw.n)
+			}
+			return nil
+		},
+	)
+}
+
+// PadTo maps m within a fixed size record of targetSize bytes.
+// On write, the remainder of the record after m is filled with the fill byte.
+// On read, the remainder of the record after m is skipped.
+// ErrSizeMismatch is returned if m reads or writes more than targetSize bytes.
+// On write, the output of m is buffered so nothing is written if it's too large.
+func PadTo(targetSize int64, fill byte, m Mapper) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			cr := &countingReader{reader: r}
+			if err := m.Read(cr, endian); err != nil {
+				return err
+			}
+			if cr.n > targetSize {
+				return fmt.Errorf("%w: read %d bytes, which exceeds the record size of %d", ErrSizeMismatch, cr.n, targetSize)
+			}
+			if _, err := io.CopyN(io.Discard, r, targetSize-cr.n); err != nil {
+				if errors.Is(err, io.EOF) {
+					return io.ErrUnexpectedEOF
+				}
+				return err
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			if err := m.Write(&buf, endian); err != nil {
+				return err
+			}
+			if n := int64(buf.Len()); n > targetSize {
+				return fmt.Errorf("%w: wrote %d bytes, which exceeds the record size of %d", ErrSizeMismatch, n, targetSize)
+			}
+			buf.Write(bytes.Repeat([]byte{fill}, int(targetSize)-buf.Len()))
+			_, err := buf.WriteTo(w)
+			return err
+		},
+	)
+}
+
+// nextPowerOfTwo returns the smallest power of two that is at least n, or 0 if n is 0.
+func nextPowerOfTwo(n int64) int64 {
+	if n <= 1 {
+		return n
+	}
+	return 1 << bits.Len64(uint64(n-1))
+}
+
+// PadToPowerOfTwo maps m within a record that is padded to the next power of two of its size, as is done in some memory-pool formats.
+// A record that is already a power of two in size is not padded, and an empty record is left empty.
+// On write, the remainder of the record after m is filled with the fill byte.
+// On read, the remainder of the record after m is skipped.
+func PadToPowerOfTwo(m Mapper, fill byte) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			cr := &countingReader{reader: r}
+			if err := m.Read(cr, endian); err != nil {
+				return err
+			}
+			if _, err := io.CopyN(io.Discard, r, nextPowerOfTwo(cr.n)-cr.n); err != nil {
+				if errors.Is(err, io.EOF) {
+					return io.ErrUnexpectedEOF
+				}
+				return err
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			cw := &countingWriter{writer: w}
+			if err := m.Write(cw, endian); err != nil {
+				return err
+			}
+			_, err := w.Write(bytes.Repeat([]byte{fill}, int(nextPowerOfTwo(cw.n)-cw.n)))
+			return err
+		},
+	)
+}
+
+// DefaultProgressSteps is the number of times WithProgress will call its report function over the expected total, not counting the final report.
+const DefaultProgressSteps = 100
+
+// WithProgress reports the number of bytes read or written by m, which is useful for showing a progress bar when mapping a large file.
+// The report function is called each time another 1/DefaultProgressSteps of total has been processed, and a final time with the number of bytes processed once m completes successfully.
+// The final count is only reported once, even if it falls on a reporting threshold.
+// Use WithProgressEvery to control how often report is called.
+func WithProgress(m Mapper, total int64, report func(done int64)) Mapper {
+	every := total / DefaultProgressSteps
+	if every < 1 {
+		every = 1
+	}
+	return WithProgressEvery(m, every, report)
+}
+
+// WithProgressEvery is the same as WithProgress, except that report is called each time at least every bytes have been processed since the last report.
+// ErrInvalidGranularity is returned if every is less than 1.
+func WithProgressEvery(m Mapper, every int64, report func(done int64)) Mapper {
+	if m == nil || report == nil {
+		return nilMapping
+	}
+	if every < 1 {
+		return errMapping(fmt.Errorf("%w: got %d", ErrInvalidGranularity, every))
+	}
+	// progress calls report once the count has crossed the next reporting threshold, and finish makes sure the final count is reported exactly once.
+	type tracker struct {
+		next, last int64
+	}
+	progress := func(t *tracker) func(n int64) {
+		t.next, t.last = every, -1
+		return func(n int64) {
+			if n >= t.next {
+				report(n)
+				t.next, t.last = n+every, n
+			}
+		}
+	}
+	finish := func(t *tracker, n int64) {
+		if n != t.last {
+			report(n)
+		}
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var t tracker
+			cr := &countingReader{reader: r, progress: progress(&t)}
+			if err := m.Read(cr, endian); err != nil {
+				return err
+			}
+			finish(&t, cr.n)
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var t tracker
+			cw := &countingWriter{writer: w, progress: progress(&t)}
+			if err := m.Write(cw, endian); err != nil {
+				return err
+			}
+			finish(&t, cw.n)
+			return nil
+		},
+	)
+}
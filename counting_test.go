@@ -0,0 +1,117 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestExactSize(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		a      uint16
+		b      uint32
+	)
+	m := ExactSize(MapSequence(Int(&a), Int(&b)), 6)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.NoError(t, m.Read(&buf, endian))
+
+	m = ExactSize(Int(&a), 4)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrSizeMismatch)
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrSizeMismatch)
+
+	buf.Reset()
+	m = ExactSize(MapSequence(Int(&a), Int(&b)), 4)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrSizeMismatch)
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrSizeMismatch)
+}
+
+func TestPadTo(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		name   = "abc"
+		after  = uint8(9)
+	)
+	m := MapSequence(
+		PadTo(8, ' ', NullTermString(&name)),
+		Int(&after),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "abc\x00    \x09", buf.String())
+
+	name, after = "", 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, "abc", name)
+	assert.Equal(t, uint8(9), after)
+
+	name = "too long!"
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrSizeMismatch)
+	assert.Equal(t, 0, buf.Len(), "Nothing should be written for an oversized value")
+	buf.Reset()
+	buf.WriteString("too long!\x00")
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrSizeMismatch)
+
+	buf.Reset()
+	buf.WriteString("abc\x00  ")
+	assert.ErrorIs(t, m.Read(&buf, endian), io.ErrUnexpectedEOF, "Padding is short")
+}
+
+func TestPadToPowerOfTwo(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		name   = "abcde"
+		after  = uint8(9)
+	)
+	m := MapSequence(
+		PadToPowerOfTwo(NullTermString(&name), ' '),
+		Int(&after),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "abcde\x00  \x09", buf.String())
+
+	name, after = "", 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, "abcde", name)
+	assert.Equal(t, uint8(9), after)
+
+	name = "abc"
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "abc\x00\x09", buf.String(), "Power of two sizes should not be padded")
+
+	buf.Reset()
+	buf.WriteString("abcde\x00 ")
+	assert.ErrorIs(t, PadToPowerOfTwo(NullTermString(&name), ' ').Read(&buf, endian), io.ErrUnexpectedEOF)
+
+	for n, expected := range map[int64]int64{0: 0, 1: 1, 2: 2, 3: 4, 5: 8, 1023: 1024, 1025: 2048} {
+		assert.Equal(t, expected, nextPowerOfTwo(n))
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		endian  = binary.BigEndian
+		data    = make([]uint32, 1000)
+		reports []int64
+	)
+	report := func(done int64) {
+		reports = append(reports, done)
+	}
+	m := WithProgress(Slice(&data, uint16(len(data)), Int[uint32]), 4000, report)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Len(t, reports, DefaultProgressSteps)
+	assert.Equal(t, int64(40), reports[0])
+	assert.Equal(t, int64(4000), reports[len(reports)-1])
+
+	reports = nil
+	m = WithProgressEvery(Slice(&data, uint16(len(data)), Int[uint32]), 1500, report)
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []int64{1500, 3000, 4000}, reports)
+
+	assert.ErrorIs(t, WithProgressEvery(Int(&data[0]), 0, report).Read(&buf, endian), ErrInvalidGranularity)
+}
@@ -0,0 +1,199 @@
+package bin
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// padding maps n bytes of padding.
+// Padding is discarded on read, and written as zero bytes.
+func padding(n int64) Mapper {
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			_, err := io.CopyN(io.Discard, r, n)
+			return err
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			_, err := w.Write(make([]byte, n))
+			return err
+		},
+	)
+}
+
+type cField struct {
+	size   int
+	align  int
+	mapper Mapper
+}
+
+// CStructBuilder is used to declare the fields of a C struct, so a Mapper can be created that matches the struct's memory layout.
+// Padding is automatically inserted between fields and at the end of the struct according to the alignment rules of the selected packing.
+// Created with CStruct.
+type CStructBuilder struct {
+	pack   int
+	fields []cField
+}
+
+// CStruct creates a CStructBuilder that uses the given packing value, which has the same meaning as #pragma pack(n).
+// A pack of 0 uses the natural alignment of each field, a pack of 1 produces a packed struct with no padding, and other values cap the alignment of each field at pack bytes.
+func CStruct(pack int) *CStructBuilder {
+	if pack < 0 {
+		pack = 0
+	}
+	return &CStructBuilder{pack: pack}
+}
+
+// Field adds a field with the given size and natural alignment, which is mapped with m.
+// The other methods of CStructBuilder should be preferred, but this can be used for types that aren't directly supported.
+func (b *CStructBuilder) Field(m Mapper, size, align int) *CStructBuilder {
+	if align < 1 {
+		align = 1
+	}
+	b.fields = append(b.fields, cField{size: size, align: align, mapper: m})
+	return b
+}
+
+// Char adds a C char field.
+func (b *CStructBuilder) Char(c *byte) *CStructBuilder {
+	return b.Field(Byte(c), 1, 1)
+}
+
+// Bool adds a C bool field.
+func (b *CStructBuilder) Bool(v *bool) *CStructBuilder {
+	return b.Field(Bool(v), 1, 1)
+}
+
+// Int8 adds an int8_t field.
+func (b *CStructBuilder) Int8(i *int8) *CStructBuilder {
+	return b.Field(Int(i), 1, 1)
+}
+
+// Uint8 adds a uint8_t field.
+func (b *CStructBuilder) Uint8(i *uint8) *CStructBuilder {
+	return b.Field(Int(i), 1, 1)
+}
+
+// Int16 adds an int16_t field.
+func (b *CStructBuilder) Int16(i *int16) *CStructBuilder {
+	return b.Field(Int(i), 2, 2)
+}
+
+// Uint16 adds a uint16_t field.
+func (b *CStructBuilder) Uint16(i *uint16) *CStructBuilder {
+	return b.Field(Int(i), 2, 2)
+}
+
+// Int32 adds an int32_t field.
+func (b *CStructBuilder) Int32(i *int32) *CStructBuilder {
+	return b.Field(Int(i), 4, 4)
+}
+
+// Uint32 adds a uint32_t field.
+func (b *CStructBuilder) Uint32(i *uint32) *CStructBuilder {
+	return b.Field(Int(i), 4, 4)
+}
+
+// Int64 adds an int64_t field.
+func (b *CStructBuilder) Int64(i *int64) *CStructBuilder {
+	return b.Field(Int(i), 8, 8)
+}
+
+// Uint64 adds a uint64_t field.
+func (b *CStructBuilder) Uint64(i *uint64) *CStructBuilder {
+	return b.Field(Int(i), 8, 8)
+}
+
+// Float adds a C float field.
+func (b *CStructBuilder) Float(f *float32) *CStructBuilder {
+	return b.Field(Float(f), 4, 4)
+}
+
+// Double adds a C double field.
+func (b *CStructBuilder) Double(f *float64) *CStructBuilder {
+	return b.Field(Float(f), 8, 8)
+}
+
+// CharArray adds a char[length] field, which is mapped as a FixedString.
+func (b *CStructBuilder) CharArray(s *string, length int) *CStructBuilder {
+	return b.Field(FixedString(s, length), length, 1)
+}
+
+// Struct adds a nested struct field.
+// The nested struct's alignment is the largest alignment of its fields.
+func (b *CStructBuilder) Struct(nested *CStructBuilder) *CStructBuilder {
+	return b.Field(nested.Mapper(), nested.Size(), nested.Align())
+}
+
+func (b *CStructBuilder) fieldAlign(f cField) int {
+	if b.pack > 0 && b.pack < f.align {
+		return b.pack
+	}
+	return f.align
+}
+
+// Align returns the alignment of the struct, which is the largest alignment of its fields.
+func (b *CStructBuilder) Align() int {
+	align := 1
+	for _, f := range b.fields {
+		if fa := b.fieldAlign(f); fa > align {
+			align = fa
+		}
+	}
+	return align
+}
+
+func alignUp(offset, align int) int {
+	if rem := offset % align; rem != 0 {
+		return offset + align - rem
+	}
+	return offset
+}
+
+// layout returns the offset of each field, and the total size of the struct including trailing padding.
+func (b *CStructBuilder) layout() ([]int, int) {
+	var (
+		offsets = make([]int, len(b.fields))
+		offset  int
+	)
+	for i, f := range b.fields {
+		offset = alignUp(offset, b.fieldAlign(f))
+		offsets[i] = offset
+		offset += f.size
+	}
+	return offsets, alignUp(offset, b.Align())
+}
+
+// Offset returns the offset of the i-th field, or -1 if i is out of range.
+func (b *CStructBuilder) Offset(i int) int {
+	if i < 0 || i >= len(b.fields) {
+		return -1
+	}
+	offsets, _ := b.layout()
+	return offsets[i]
+}
+
+// Size returns the total size of the struct, including padding.
+func (b *CStructBuilder) Size() int {
+	_, size := b.layout()
+	return size
+}
+
+// Mapper creates a Mapper that maps each field in order, with padding inserted to match the struct's layout.
+func (b *CStructBuilder) Mapper() Mapper {
+	var (
+		offsets, size = b.layout()
+		mappers       []Mapper
+		offset        int
+	)
+	for i, f := range b.fields {
+		if pad := offsets[i] - offset; pad > 0 {
+			mappers = append(mappers, padding(int64(pad)))
+		}
+		mappers = append(mappers, f.mapper)
+		offset = offsets[i] + f.size
+	}
+	if pad := size - offset; pad > 0 {
+		mappers = append(mappers, padding(int64(pad)))
+	}
+	return MapSequence(mappers...)
+}
@@ -0,0 +1,78 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCStruct(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.LittleEndian
+		data   = struct {
+			a byte
+			b uint32
+			c uint16
+			d float64
+			e byte
+		}{
+			a: 1,
+			b: 2,
+			c: 3,
+			d: 4,
+			e: 5,
+		}
+	)
+	build := func(pack int) *CStructBuilder {
+		return CStruct(pack).
+			Char(&data.a).
+			Uint32(&data.b).
+			Uint16(&data.c).
+			Double(&data.d).
+			Char(&data.e)
+	}
+
+	natural := build(0)
+	assert.Equal(t, 32, natural.Size())
+	assert.Equal(t, 8, natural.Align())
+	assert.Equal(t, []int{0, 4, 8, 16, 24}, []int{natural.Offset(0), natural.Offset(1), natural.Offset(2), natural.Offset(3), natural.Offset(4)})
+	assert.NoError(t, natural.Mapper().Write(&buf, endian))
+	out := buf.Bytes()
+	assert.Len(t, out, 32)
+	assert.Equal(t, []byte{1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0}, out[:16])
+
+	packed := build(1)
+	assert.Equal(t, 16, packed.Size())
+	assert.Equal(t, 1, packed.Align())
+
+	pack4 := build(4)
+	assert.Equal(t, 24, pack4.Size())
+	assert.Equal(t, 12, pack4.Offset(3))
+
+	data.a, data.b, data.c, data.d, data.e = 0, 0, 0, 0, 0
+	assert.NoError(t, natural.Mapper().Read(&buf, endian))
+	assert.Equal(t, byte(1), data.a)
+	assert.Equal(t, uint32(2), data.b)
+	assert.Equal(t, uint16(3), data.c)
+	assert.Equal(t, 4.0, data.d)
+	assert.Equal(t, byte(5), data.e)
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestCStruct_Nested(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.LittleEndian
+		tag    byte
+		x, y   int32
+	)
+	point := CStruct(0).Int32(&x).Int32(&y)
+	outer := CStruct(0).Char(&tag).Struct(point)
+	assert.Equal(t, 12, outer.Size())
+
+	tag, x, y = 1, 2, 3
+	assert.NoError(t, outer.Mapper().Write(&buf, endian))
+	assert.Equal(t, []byte{1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0}, buf.Bytes())
+}
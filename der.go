@@ -0,0 +1,298 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+var (
+	ErrInvalidDER = errors.New("invalid DER encoding")
+)
+
+// DERClass is the class of an ASN.1 tag.
+type DERClass byte
+
+const (
+	DERUniversal       DERClass = 0
+	DERApplication     DERClass = 1
+	DERContextSpecific DERClass = 2
+	DERPrivate         DERClass = 3
+)
+
+// ASN1Tag identifies the type of an ASN.1 value.
+type ASN1Tag struct {
+	Class DERClass
+	// Constructed is true if the value contains other TLV encoded values, like a SEQUENCE.
+	Constructed bool
+	Number      uint32
+}
+
+var (
+	derIntegerTag     = ASN1Tag{Class: DERUniversal, Number: 2}
+	derOctetStringTag = ASN1Tag{Class: DERUniversal, Number: 4}
+	derSequenceTag    = ASN1Tag{Class: DERUniversal, Constructed: true, Number: 16}
+)
+
+// DERTag maps an ASN.1 identifier octet, along with any following octets for tag numbers of 31 or more.
+// The class is encoded in the two most significant bits, followed by the constructed bit, and then the tag number.
+func DERTag(tag *ASN1Tag) Mapper {
+	if tag == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			ubr := &unbufferedByteReader{reader: r}
+			b, err := ubr.ReadByte()
+			if err != nil {
+				return err
+			}
+			tag.Class = DERClass(b >> 6)
+			tag.Constructed = b&0x20 != 0
+			tag.Number = uint32(b & 0x1F)
+			if tag.Number != 0x1F {
+				return nil
+			}
+			// High tag number form, base 128 with the high bit set on all but the last octet.
+			var num uint32
+			for i := 0; ; i++ {
+				b, err := ubr.ReadByte()
+				if err != nil {
+					if errors.Is(err, io.EOF) {
+						return io.ErrUnexpectedEOF
+					}
+					return err
+				}
+				if i == 0 && b == 0x80 {
+					return fmt.Errorf("%w: tag number has leading zeros", ErrInvalidDER)
+				}
+				if num > (1<<32-1)>>7 {
+					return fmt.Errorf("%w: tag number is too large", ErrInvalidDER)
+				}
+				num = num<<7 | uint32(b&0x7F)
+				if b&0x80 == 0 {
+					break
+				}
+			}
+			if num < 0x1F {
+				return fmt.Errorf("%w: tag number %d should use the low tag number form", ErrInvalidDER, num)
+			}
+			tag.Number = num
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			if tag.Class > DERPrivate {
+				return fmt.Errorf("%w: invalid tag class %d", ErrInvalidDER, tag.Class)
+			}
+			first := byte(tag.Class) << 6
+			if tag.Constructed {
+				first |= 0x20
+			}
+			if tag.Number < 0x1F {
+				_, err := w.Write([]byte{first | byte(tag.Number)})
+				return err
+			}
+			buf := []byte{byte(tag.Number & 0x7F)}
+			for num := tag.Number >> 7; num > 0; num >>= 7 {
+				buf = append([]byte{byte(num&0x7F) | 0x80}, buf...)
+			}
+			_, err := w.Write(append([]byte{first | 0x1F}, buf...))
+			return err
+		},
+	)
+}
+
+// DERLength maps an ASN.1 definite length in the DER form.
+// Lengths less than 128 are encoded in a single octet, and longer lengths are encoded as an octet with the high bit set and the number of following big-endian length octets.
+// On read, ErrInvalidDER is returned for the indefinite length form, or if the length is not minimally encoded, since neither is allowed in DER.
+func DERLength(length *uint64) Mapper {
+	if length == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			ubr := &unbufferedByteReader{reader: r}
+			b, err := ubr.ReadByte()
+			if err != nil {
+				return err
+			}
+			if b&0x80 == 0 {
+				*length = uint64(b)
+				return nil
+			}
+			n := int(b & 0x7F)
+			switch {
+			case n == 0:
+				return fmt.Errorf("%w: indefinite length", ErrInvalidDER)
+			case n > 8:
+				return fmt.Errorf("%w: %d length octets", ErrInvalidDER, n)
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				if errors.Is(err, io.EOF) {
+					return io.ErrUnexpectedEOF
+				}
+				return err
+			}
+			if buf[0] == 0 {
+				return fmt.Errorf("%w: length has leading zeros", ErrInvalidDER)
+			}
+			var val uint64
+			for _, b := range buf {
+				val = val<<8 | uint64(b)
+			}
+			if val < 0x80 {
+				return fmt.Errorf("%w: length %d should use the short form", ErrInvalidDER, val)
+			}
+			*length = val
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			if *length < 0x80 {
+				_, err := w.Write([]byte{byte(*length)})
+				return err
+			}
+			var buf []byte
+			for val := *length; val > 0; val >>= 8 {
+				buf = append([]byte{byte(val)}, buf...)
+			}
+			_, err := w.Write(append([]byte{0x80 | byte(len(buf))}, buf...))
+			return err
+		},
+	)
+}
+
+// DERValue maps a complete TLV encoded value with the given tag, where the contents are mapped with m.
+// On read, ErrInvalidDER is returned if the tag doesn't match, m is bounded to the length of the contents, and ErrSizeMismatch is returned if m doesn't read all of the contents.
+// On write, the contents are buffered so the length can be written first.
+func DERValue(tag ASN1Tag, m Mapper) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				found  ASN1Tag
+				length uint64
+			)
+			if err := DERTag(&found).Read(r, endian); err != nil {
+				return err
+			}
+			if found != tag {
+				return fmt.Errorf("%w: expected tag %+v, but found %+v", ErrInvalidDER, tag, found)
+			}
+			if err := DERLength(&length).Read(r, endian); err != nil {
+				return err
+			}
+			lr, err := limitTo(r, length)
+			if err != nil {
+				return err
+			}
+			if err := m.Read(lr, endian); err != nil {
+				return err
+			}
+			if lr.N != 0 {
+				return fmt.Errorf("%w: %d bytes of the contents were not read", ErrSizeMismatch, lr.N)
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			if err := m.Write(&buf, endian); err != nil {
+				return err
+			}
+			length := uint64(buf.Len())
+			if err := MapSequence(DERTag(&tag), DERLength(&length)).Write(w, endian); err != nil {
+				return err
+			}
+			_, err := buf.WriteTo(w)
+			return err
+		},
+	)
+}
+
+// DERSequence maps an ASN.1 SEQUENCE, where the fields of the sequence are mapped in order with the given mappers.
+func DERSequence(fields ...Mapper) Mapper {
+	return DERValue(derSequenceTag, MapSequence(fields...))
+}
+
+// DERInteger maps an ASN.1 INTEGER as a big.Int, which is encoded as a minimal two's complement big-endian value.
+// On read, ErrInvalidDER is returned if the contents are empty or not minimally encoded.
+func DERInteger(target *big.Int) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return DERValue(derIntegerTag, Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			buf, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			switch {
+			case len(buf) == 0:
+				return fmt.Errorf("%w: empty integer", ErrInvalidDER)
+			case len(buf) > 1 && (buf[0] == 0x00 && buf[1]&0x80 == 0 || buf[0] == 0xFF && buf[1]&0x80 != 0):
+				return fmt.Errorf("%w: integer is not minimally encoded", ErrInvalidDER)
+			}
+			if buf[0]&0x80 == 0 {
+				target.SetBytes(buf)
+				return nil
+			}
+			// Negative, so invert to get the magnitude minus one.
+			for i := range buf {
+				buf[i] = ^buf[i]
+			}
+			target.SetBytes(buf)
+			target.Add(target, big.NewInt(1))
+			target.Neg(target)
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			var buf []byte
+			switch target.Sign() {
+			case 0:
+				buf = []byte{0}
+			case 1:
+				buf = target.Bytes()
+				if buf[0]&0x80 != 0 {
+					buf = append([]byte{0}, buf...)
+				}
+			default:
+				magnitude := new(big.Int).Neg(target)
+				buf = magnitude.Sub(magnitude, big.NewInt(1)).Bytes()
+				for i := range buf {
+					buf[i] = ^buf[i]
+				}
+				if len(buf) == 0 || buf[0]&0x80 == 0 {
+					buf = append([]byte{0xFF}, buf...)
+				}
+			}
+			_, err := w.Write(buf)
+			return err
+		},
+	))
+}
+
+// DEROctetString maps an ASN.1 OCTET STRING.
+func DEROctetString(target *[]byte) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return DERValue(derOctetStringTag, Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			buf, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			*target = buf
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			_, err := w.Write(*target)
+			return err
+		},
+	))
+}
@@ -0,0 +1,126 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestDERSequence(t *testing.T) {
+	type record struct {
+		Serial *big.Int
+		Data   []byte
+	}
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		serial = big.NewInt(-129)
+		data   = bytes.Repeat([]byte{0xAB}, 200)
+		mapper = DERSequence(DERInteger(serial), DEROctetString(&data))
+	)
+	expected, err := asn1.Marshal(record{Serial: big.NewInt(-129), Data: data})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mapper.Write(&buf, endian))
+	assert.Equal(t, expected, buf.Bytes())
+
+	serial.SetInt64(0)
+	data = nil
+	assert.NoError(t, mapper.Read(&buf, endian))
+	assert.Equal(t, int64(-129), serial.Int64())
+	assert.Equal(t, bytes.Repeat([]byte{0xAB}, 200), data)
+}
+
+func TestDERInteger(t *testing.T) {
+	var endian = binary.BigEndian
+	for _, val := range []int64{0, 1, 127, 128, 255, 256, -1, -128, -129, -256, -257, 1 << 40, -(1 << 40)} {
+		var (
+			buf    bytes.Buffer
+			target = big.NewInt(val)
+		)
+		expected, err := asn1.Marshal(big.NewInt(val))
+		assert.NoError(t, err)
+		assert.NoError(t, DERInteger(target).Write(&buf, endian))
+		assert.Equal(t, expected, buf.Bytes(), "value %d", val)
+
+		target.SetInt64(42)
+		assert.NoError(t, DERInteger(target).Read(&buf, endian))
+		assert.Equal(t, val, target.Int64())
+	}
+
+	var target big.Int
+	assert.ErrorIs(t, DERInteger(&target).Read(bytes.NewReader([]byte{0x02, 0x02, 0x00, 0x7F}), endian), ErrInvalidDER)
+	assert.ErrorIs(t, DERInteger(&target).Read(bytes.NewReader([]byte{0x02, 0x02, 0xFF, 0x80}), endian), ErrInvalidDER)
+	assert.ErrorIs(t, DERInteger(&target).Read(bytes.NewReader([]byte{0x02, 0x00}), endian), ErrInvalidDER)
+	assert.ErrorIs(t, DERInteger(&target).Read(bytes.NewReader([]byte{0x04, 0x01, 0x00}), endian), ErrInvalidDER)
+}
+
+func TestDERTag(t *testing.T) {
+	var endian = binary.BigEndian
+	tests := map[string]struct {
+		tag      ASN1Tag
+		expected []byte
+	}{
+		"Universal sequence": {ASN1Tag{Class: DERUniversal, Constructed: true, Number: 16}, []byte{0x30}},
+		"Context specific":   {ASN1Tag{Class: DERContextSpecific, Constructed: true, Number: 3}, []byte{0xA3}},
+		"Application":        {ASN1Tag{Class: DERApplication, Number: 30}, []byte{0x5E}},
+		"High tag number":    {ASN1Tag{Class: DERPrivate, Number: 31}, []byte{0xDF, 0x1F}},
+		"Multi-byte number":  {ASN1Tag{Class: DERContextSpecific, Number: 201}, []byte{0x9F, 0x81, 0x49}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				buf bytes.Buffer
+				tag = tc.tag
+			)
+			assert.NoError(t, DERTag(&tag).Write(&buf, endian))
+			assert.Equal(t, tc.expected, buf.Bytes())
+			tag = ASN1Tag{}
+			assert.NoError(t, DERTag(&tag).Read(&buf, endian))
+			assert.Equal(t, tc.tag, tag)
+		})
+	}
+
+	var tag ASN1Tag
+	assert.ErrorIs(t, DERTag(&tag).Read(bytes.NewReader([]byte{0x1F, 0x1E}), endian), ErrInvalidDER)
+	assert.ErrorIs(t, DERTag(&tag).Read(bytes.NewReader([]byte{0x1F, 0x80, 0x40}), endian), ErrInvalidDER)
+}
+
+func TestDERLength(t *testing.T) {
+	var endian = binary.BigEndian
+	tests := map[uint64][]byte{
+		0:       {0x00},
+		127:     {0x7F},
+		128:     {0x81, 0x80},
+		256:     {0x82, 0x01, 0x00},
+		1 << 24: {0x84, 0x01, 0x00, 0x00, 0x00},
+	}
+	for length, expected := range tests {
+		var (
+			buf bytes.Buffer
+			val = length
+		)
+		assert.NoError(t, DERLength(&val).Write(&buf, endian))
+		assert.Equal(t, expected, buf.Bytes())
+		val = 0
+		assert.NoError(t, DERLength(&val).Read(&buf, endian))
+		assert.Equal(t, length, val)
+	}
+
+	var length uint64
+	assert.ErrorIs(t, DERLength(&length).Read(bytes.NewReader([]byte{0x80}), endian), ErrInvalidDER, "Indefinite length")
+	assert.ErrorIs(t, DERLength(&length).Read(bytes.NewReader([]byte{0x81, 0x7F}), endian), ErrInvalidDER, "Should be short form")
+	assert.ErrorIs(t, DERLength(&length).Read(bytes.NewReader([]byte{0x82, 0x00, 0x80}), endian), ErrInvalidDER, "Leading zeros")
+}
+
+func TestDERValue_Unread(t *testing.T) {
+	var (
+		endian = binary.BigEndian
+		b      byte
+	)
+	err := DERValue(ASN1Tag{Number: 4}, Byte(&b)).Read(bytes.NewReader([]byte{0x04, 0x02, 0x01, 0x02}), endian)
+	assert.ErrorIs(t, err, ErrSizeMismatch)
+}
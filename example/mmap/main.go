@@ -0,0 +1,73 @@
+//go:build unix
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	bin "github.com/saylorsolutions/binmap"
+	"os"
+	"syscall"
+)
+
+// Index is a large, read-heavy file with a table of record offsets at the start.
+// The file is memory-mapped, so reading a record is just a copy from memory rather than a system call.
+type Index struct {
+	data    []byte
+	src     *bytes.Reader
+	count   uint32
+	offsets []uint32
+}
+
+type Record struct {
+	id   uint64
+	name string
+}
+
+func OpenIndex(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{data: data, src: bytes.NewReader(data)}
+	if err := bin.LenSlice(&idx.offsets, &idx.count, bin.Int[uint32]).Read(idx.src, binary.BigEndian); err != nil {
+		_ = idx.Close()
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Record reads only the i'th record, directly from its offset in the mapped file.
+func (idx *Index) Record(i int) (*Record, error) {
+	if i < 0 || i >= len(idx.offsets) {
+		return nil, fmt.Errorf("%w: record %d of %d", bin.ErrOutOfBounds, i, len(idx.offsets))
+	}
+	var (
+		rec    Record
+		offset = int64(idx.offsets[i])
+	)
+	m := bin.AtOffset(idx.src, &offset, bin.MapSequence(
+		bin.Int(&rec.id),
+		bin.NullTermString(&rec.name),
+	))
+	if err := m.Read(idx.src, binary.BigEndian); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (idx *Index) Close() error {
+	return syscall.Munmap(idx.data)
+}
@@ -0,0 +1,362 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	ErrInvalidFrame = errors.New("invalid frame")
+)
+
+const (
+	stuffXor = 0x20
+)
+
+// ByteStuffed frames the output of m between delimiter bytes, escaping any occurrence of delimiter or escape within the frame.
+// An escaped byte is written as the escape byte, followed by the original byte XOR 0x20, as is done in PPP/HDLC framing.
+// On read, the frame will be read and unescaped in its entirety before being read with m.
+// ErrInvalidFrame is returned if the frame doesn't start with the delimiter, or contains an invalid escape sequence.
+func ByteStuffed(m Mapper, delimiter, escape byte) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	if delimiter == escape {
+		return errMapping(fmt.Errorf("%w: delimiter and escape bytes must be different", ErrInvalidFrame))
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				buf bytes.Buffer
+				ubr = &unbufferedByteReader{reader: r}
+			)
+			b, err := ubr.ReadByte()
+			if err != nil {
+				return err
+			}
+			if b != delimiter {
+				return fmt.Errorf("%w: expected leading delimiter 0x%02X, but found 0x%02X", ErrInvalidFrame, delimiter, b)
+			}
+			for {
+				b, err = ubr.ReadByte()
+				if err != nil {
+					if errors.Is(err, io.EOF) {
+						return io.ErrUnexpectedEOF
+					}
+					return err
+				}
+				switch b {
+				case delimiter:
+					return m.Read(&buf, endian)
+				case escape:
+					b, err = ubr.ReadByte()
+					if err != nil {
+						if errors.Is(err, io.EOF) {
+							return io.ErrUnexpectedEOF
+						}
+						return err
+					}
+					b ^= stuffXor
+					if b != delimiter && b != escape {
+						return fmt.Errorf("%w: invalid escaped byte 0x%02X", ErrInvalidFrame, b^stuffXor)
+					}
+				}
+				buf.WriteByte(b)
+			}
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			if err := m.Write(&buf, endian); err != nil {
+				return err
+			}
+			out := make([]byte, 0, buf.Len()+2)
+			out = append(out, delimiter)
+			for _, b := range buf.Bytes() {
+				if b == delimiter || b == escape {
+					out = append(out, escape, b^stuffXor)
+					continue
+				}
+				out = append(out, b)
+			}
+			out = append(out, delimiter)
+			_, err := w.Write(out)
+			return err
+		},
+	)
+}
+
+// COBS frames the output of m with Consistent Overhead Byte Stuffing, which eliminates zero bytes from the frame so a zero byte can be used as a frame delimiter.
+// On write, the encoded frame is followed by a zero byte.
+// On read, bytes will be read until the zero delimiter is found, then decoded and read with m.
+// ErrInvalidFrame is returned if the frame is not validly COBS encoded.
+func COBS(m Mapper) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				buf bytes.Buffer
+				ubr = &unbufferedByteReader{reader: r}
+			)
+			for {
+				b, err := ubr.ReadByte()
+				if err != nil {
+					if errors.Is(err, io.EOF) && buf.Len() > 0 {
+						return io.ErrUnexpectedEOF
+					}
+					return err
+				}
+				if b == 0 {
+					break
+				}
+				buf.WriteByte(b)
+			}
+			decoded, err := cobsDecode(buf.Bytes())
+			if err != nil {
+				return err
+			}
+			return m.Read(bytes.NewReader(decoded), endian)
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			if err := m.Write(&buf, endian); err != nil {
+				return err
+			}
+			out := append(cobsEncode(buf.Bytes()), 0)
+			_, err := w.Write(out)
+			return err
+		},
+	)
+}
+
+// cobsEncode encodes src such that it contains no zero bytes.
+// The trailing zero delimiter is not included.
+func cobsEncode(src []byte) []byte {
+	var (
+		out     = make([]byte, 1, len(src)+len(src)/254+2)
+		codeIdx = 0
+		code    = byte(1)
+	)
+	for i, b := range src {
+		if b != 0 {
+			out = append(out, b)
+			code++
+			if code != 0xFF {
+				continue
+			}
+			// A maximum length block at the end of the input doesn't need a following block.
+			if i == len(src)-1 {
+				break
+			}
+		}
+		out[codeIdx] = code
+		codeIdx = len(out)
+		out = append(out, 0)
+		code = 1
+	}
+	out[codeIdx] = code
+	return out
+}
+
+// cobsDecode reverses cobsEncode.
+// The given data should not include the trailing zero delimiter.
+func cobsDecode(src []byte) ([]byte, error) {
+	out := make([]byte, 0, len(src))
+	for i := 0; i < len(src); {
+		code := int(src[i])
+		if code == 0 {
+			return nil, fmt.Errorf("%w: unexpected zero byte in COBS frame at offset %d", ErrInvalidFrame, i)
+		}
+		i++
+		end := i + code - 1
+		if end > len(src) {
+			return nil, fmt.Errorf("%w: COBS block at offset %d exceeds frame length", ErrInvalidFrame, i-1)
+		}
+		out = append(out, src[i:end]...)
+		i = end
+		if code < 0xFF && i < len(src) {
+			out = append(out, 0)
+		}
+	}
+	return out, nil
+}
+
+// SevenBitPacked maps a byte slice such that every byte written has its high bit clear, so arbitrary binary data can pass through a 7-bit clean transport.
+// The length of the slice is written first as five 7-bit groups, most significant group first.
+// The data is then written in groups of up to 7 bytes, each preceded by a byte containing the high bits of the group, with the first byte's high bit in the least significant bit.
+// The remaining 7 bits of each byte in the group follow, so 7 input bytes produce 8 output bytes, and a final partial group of n bytes produces n+1 output bytes.
+// ErrInvalidFrame is returned on read if any byte has its high bit set.
+func SevenBitPacked(buf *[]byte) Mapper {
+	if buf == nil {
+		return nilMapping
+	}
+	const maxLen = 1<<32 - 1
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			prefix := make([]byte, 5)
+			if _, err := io.ReadFull(r, prefix); err != nil {
+				return err
+			}
+			var length uint64
+			for _, b := range prefix {
+				if b&0x80 != 0 {
+					return fmt.Errorf("%w: high bit set in 7-bit length", ErrInvalidFrame)
+				}
+				length = length<<7 | uint64(b)
+			}
+			if length > maxLen {
+				return fmt.Errorf("%w: 7-bit length %d is too large", ErrInvalidFrame, length)
+			}
+			// The length may not fit in an int on 32-bit platforms.
+			if length > math.MaxInt {
+				return fmt.Errorf("%w: 7-bit length %d", ErrSizeOverflow, length)
+			}
+			var (
+				out   = []byte{}
+				group = make([]byte, 8)
+			)
+			for remaining := int(length); remaining > 0; {
+				n := 7
+				if remaining < n {
+					n = remaining
+				}
+				if _, err := io.ReadFull(r, group[:n+1]); err != nil {
+					if errors.Is(err, io.EOF) {
+						return io.ErrUnexpectedEOF
+					}
+					return err
+				}
+				for _, b := range group[:n+1] {
+					if b&0x80 != 0 {
+						return fmt.Errorf("%w: high bit set in 7-bit data", ErrInvalidFrame)
+					}
+				}
+				highBits := group[0]
+				for i := 0; i < n; i++ {
+					out = append(out, group[i+1]|(highBits>>i&1)<<7)
+				}
+				remaining -= n
+			}
+			*buf = out
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			data := *buf
+			if uint64(len(data)) > maxLen {
+				return fmt.Errorf("%w: %d", ErrSizeOverflow, len(data))
+			}
+			var (
+				length = uint64(len(data))
+				out    = make([]byte, 5, 5+len(data)+len(data)/7+1)
+			)
+			for i := 4; i >= 0; i-- {
+				out[i] = byte(length & 0x7F)
+				length >>= 7
+			}
+			for len(data) > 0 {
+				n := 7
+				if len(data) < n {
+					n = len(data)
+				}
+				var highBits byte
+				for i, b := range data[:n] {
+					highBits |= (b >> 7) << i
+				}
+				out = append(out, highBits)
+				for _, b := range data[:n] {
+					out = append(out, b&0x7F)
+				}
+				data = data[n:]
+			}
+			_, err := w.Write(out)
+			return err
+		},
+	)
+}
+
+// SeparatedSlice maps elements that are separated by the sep byte, such as newline or 0x1E (record separator) delimited records within otherwise binary data.
+// On read, elements are read until EOF, and each element is read from the bytes up to the next separator with the Mapper returned from mapVal.
+// ErrSizeMismatch is returned if an element's Mapper doesn't consume exactly the bytes before the separator, and io.ErrUnexpectedEOF is returned if it needs more bytes than are available.
+// On write, elements are joined by sep, without a trailing separator.
+// ErrInvalidFrame is returned if an element's output contains the separator, since it couldn't be read back.
+// Note that an empty source is read as an empty slice, so a slice with a single empty element can't be round-tripped.
+func SeparatedSlice[E any](target *[]E, sep byte, mapVal func(*E) Mapper) Mapper {
+	if target == nil || mapVal == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				elems []E
+				buf   []byte
+				ubr   = &unbufferedByteReader{reader: r}
+				empty = true
+			)
+			readElem := func() error {
+				var (
+					e  E
+					br = bytes.NewReader(buf)
+				)
+				if err := mapVal(&e).Read(br, endian); err != nil {
+					// The element has started once a separator or any of its bytes are consumed, so running out of bytes is never a clean end.
+					if errors.Is(err, io.EOF) {
+						return io.ErrUnexpectedEOF
+					}
+					return err
+				}
+				if br.Len() > 0 {
+					return fmt.Errorf("%w: element %d has %d bytes remaining before the separator", ErrSizeMismatch, len(elems), br.Len())
+				}
+				elems = append(elems, e)
+				buf = nil
+				return nil
+			}
+			for {
+				b, err := ubr.ReadByte()
+				if err != nil {
+					if !errors.Is(err, io.EOF) {
+						return err
+					}
+					if !empty {
+						if err := readElem(); err != nil {
+							return err
+						}
+					}
+					*target = elems
+					return nil
+				}
+				empty = false
+				if b == sep {
+					if err := readElem(); err != nil {
+						return err
+					}
+					continue
+				}
+				buf = append(buf, b)
+			}
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			for i := range *target {
+				if i > 0 {
+					buf.WriteByte(sep)
+				}
+				start := buf.Len()
+				e := (*target)[i]
+				if err := mapVal(&e).Write(&buf, endian); err != nil {
+					return err
+				}
+				if bytes.IndexByte(buf.Bytes()[start:], sep) >= 0 {
+					return fmt.Errorf("%w: element %d contains the separator 0x%02X", ErrInvalidFrame, i, sep)
+				}
+			}
+			_, err := buf.WriteTo(w)
+			return err
+		},
+	)
+}
@@ -0,0 +1,155 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestByteStuffed(t *testing.T) {
+	const (
+		delim  = 0x7E
+		escape = 0x7D
+	)
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		data   = []byte{0x01, delim, escape, escape, 0x02, delim}
+	)
+	m := ByteStuffed(FixedBytes(&data, uint8(6)), delim, escape)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{delim, 0x01, escape, 0x5E, escape, 0x5D, escape, 0x5D, 0x02, escape, 0x5E, delim}, buf.Bytes())
+
+	data = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []byte{0x01, delim, escape, escape, 0x02, delim}, data)
+
+	buf.Reset()
+	buf.Write([]byte{0x01})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidFrame)
+
+	buf.Reset()
+	buf.Write([]byte{delim, 0x01, escape, 0x01, delim})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidFrame)
+
+	buf.Reset()
+	buf.Write([]byte{delim, 0x01, escape})
+	assert.ErrorIs(t, m.Read(&buf, endian), io.ErrUnexpectedEOF)
+}
+
+func TestCOBS(t *testing.T) {
+	seq := func(from, to int) []byte {
+		var out []byte
+		for i := from; i <= to; i++ {
+			out = append(out, byte(i))
+		}
+		return out
+	}
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+	tests := map[string]struct {
+		decoded []byte
+		encoded []byte
+	}{
+		"Empty":          {decoded: []byte{}, encoded: []byte{0x01, 0x00}},
+		"Single zero":    {decoded: []byte{0x00}, encoded: []byte{0x01, 0x01, 0x00}},
+		"Double zero":    {decoded: []byte{0x00, 0x00}, encoded: []byte{0x01, 0x01, 0x01, 0x00}},
+		"Embedded zero":  {decoded: []byte{0x11, 0x22, 0x00, 0x33}, encoded: []byte{0x03, 0x11, 0x22, 0x02, 0x33, 0x00}},
+		"Trailing zero":  {decoded: []byte{0x11, 0x00}, encoded: []byte{0x02, 0x11, 0x01, 0x00}},
+		"254 non-zero":   {decoded: seq(1, 254), encoded: join([]byte{0xFF}, seq(1, 254), []byte{0x00})},
+		"Leading zero":   {decoded: seq(0, 254), encoded: join([]byte{0x01, 0xFF}, seq(1, 254), []byte{0x00})},
+		"255 non-zero":   {decoded: seq(1, 255), encoded: join([]byte{0xFF}, seq(1, 254), []byte{0x02, 0xFF, 0x00})},
+		"Zero after 254": {decoded: join(seq(1, 254), []byte{0x00}), encoded: join([]byte{0xFF}, seq(1, 254), []byte{0x01, 0x01, 0x00})},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				buf    bytes.Buffer
+				endian = binary.BigEndian
+				data   = tc.decoded
+				length = uint16(len(tc.decoded))
+			)
+			m := COBS(FixedBytes(&data, length))
+			assert.NoError(t, m.Write(&buf, endian))
+			assert.Equal(t, tc.encoded, buf.Bytes())
+
+			data = nil
+			assert.NoError(t, m.Read(&buf, endian))
+			assert.Equal(t, tc.decoded, data)
+		})
+	}
+}
+
+func TestCOBS_Invalid(t *testing.T) {
+	var (
+		data = []byte{}
+		m    = COBS(FixedBytes(&data, uint8(2)))
+	)
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{0x05, 0x11, 0x00}), binary.BigEndian), ErrInvalidFrame)
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{0x03, 0x11}), binary.BigEndian), io.ErrUnexpectedEOF)
+}
+
+func TestSevenBitPacked(t *testing.T) {
+	for _, length := range []int{0, 1, 6, 7, 8, 14, 15, 300} {
+		var (
+			buf    bytes.Buffer
+			endian = binary.BigEndian
+			data   = make([]byte, length)
+		)
+		for i := range data {
+			data[i] = byte(i*37 + 0x80)
+		}
+		expected := append([]byte{}, data...)
+		m := SevenBitPacked(&data)
+		assert.NoError(t, m.Write(&buf, endian))
+		assert.Equal(t, 5+length+(length+6)/7, buf.Len())
+		for _, b := range buf.Bytes() {
+			assert.Zero(t, b&0x80, "All output bytes should be 7-bit clean")
+		}
+
+		data = nil
+		assert.NoError(t, m.Read(&buf, endian))
+		assert.Equal(t, expected, data)
+	}
+
+	var data []byte
+	assert.ErrorIs(t, SevenBitPacked(&data).Read(bytes.NewReader([]byte{0, 0, 0, 0, 1, 0x80, 0}), binary.BigEndian), ErrInvalidFrame)
+	assert.ErrorIs(t, SevenBitPacked(&data).Read(bytes.NewReader([]byte{0, 0, 0, 0, 2, 0, 1}), binary.BigEndian), io.ErrUnexpectedEOF)
+
+	// The largest length is 1<<32-1, which doesn't fit in an int on 32-bit platforms.
+	err := SevenBitPacked(&data).Read(bytes.NewReader([]byte{0x0F, 0x7F, 0x7F, 0x7F, 0x7F}), binary.BigEndian)
+	if uint64(math.MaxInt) < 1<<32-1 {
+		assert.ErrorIs(t, err, ErrSizeOverflow)
+	} else {
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestSeparatedSlice(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		vals   = []uint16{0x0102, 0x0304, 0x0506}
+	)
+	m := SeparatedSlice(&vals, 0x1E, Int[uint16])
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{1, 2, 0x1E, 3, 4, 0x1E, 5, 6}, buf.Bytes())
+
+	vals = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []uint16{0x0102, 0x0304, 0x0506}, vals)
+
+	assert.NoError(t, m.Read(bytes.NewReader(nil), endian))
+	assert.Empty(t, vals)
+
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{1, 2, 3, 0x1E, 4, 5}), endian), ErrSizeMismatch)
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{1, 2, 0x1E}), endian), io.ErrUnexpectedEOF, "A trailing separator starts an empty element")
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{1, 2, 0x1E, 3}), endian), io.ErrUnexpectedEOF, "A truncated trailing element")
+
+	vals = []uint16{0x1E00}
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrInvalidFrame)
+}
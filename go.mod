@@ -2,7 +2,10 @@ module github.com/saylorsolutions/binmap
 
 go 1.19
 
-require github.com/stretchr/testify v1.8.4
+require (
+	github.com/golang/snappy v1.0.0
+	github.com/stretchr/testify v1.8.4
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -0,0 +1,279 @@
+package bin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrIndexClosed = errors.New("index writer is closed")
+)
+
+// indexEntrySize is the encoded size of an IndexEntry.
+const indexEntrySize = 16
+
+// indexFooterSize is the size of the footer that holds the offset of the index, which is always the last 8 bytes of the stream.
+const indexFooterSize = 8
+
+// IndexEntry locates a single record in a stream with a footer index.
+type IndexEntry struct {
+	// Offset is the absolute offset of the record.
+	Offset uint64
+	// Size is the size of the record in bytes.
+	Size uint64
+}
+
+func (e *IndexEntry) mapper() Mapper {
+	return MapSequence(Int(&e.Offset), Int(&e.Size))
+}
+
+// IndexWriter writes records sequentially, and records the offset and size of each one, so an index can be written as a trailer once all records are written.
+// This is the foundation of append-friendly formats that locate records with an index at the end of the stream.
+// The stream layout is each record, followed by the index entries prefixed with their uint32 count, followed by the uint64 offset of the index.
+// The stream can be read with IndexReader.
+type IndexWriter struct {
+	w       io.WriteSeeker
+	endian  binary.ByteOrder
+	entries []IndexEntry
+	closed  bool
+}
+
+// NewIndexWriter creates an IndexWriter that writes to w with the given endian policy.
+func NewIndexWriter(w io.WriteSeeker, endian binary.ByteOrder) *IndexWriter {
+	return &IndexWriter{w: w, endian: endian}
+}
+
+// WriteRecord writes a record with m at the current position of the io.WriteSeeker, and adds it to the index.
+func (iw *IndexWriter) WriteRecord(m Mapper) error {
+	if iw.closed {
+		return ErrIndexClosed
+	}
+	offset, err := iw.w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	cw := &countingWriter{writer: iw.w}
+	if err := m.Write(cw, iw.endian); err != nil {
+		return err
+	}
+	iw.entries = append(iw.entries, IndexEntry{Offset: uint64(offset), Size: uint64(cw.n)})
+	return nil
+}
+
+// Entries returns the index entries of the records written so far.
+func (iw *IndexWriter) Entries() []IndexEntry {
+	return iw.entries
+}
+
+// Close writes the index and footer after the last record.
+// No more records may be written after Close is called.
+// This doesn't close the underlying io.WriteSeeker.
+func (iw *IndexWriter) Close() error {
+	if iw.closed {
+		return ErrIndexClosed
+	}
+	iw.closed = true
+	offset, err := iw.w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	count, err := toSize[uint32](len(iw.entries))
+	if err != nil {
+		return err
+	}
+	indexOffset := uint64(offset)
+	return MapSequence(
+		LenSlice(&iw.entries, &count, func(e *IndexEntry) Mapper {
+			return e.mapper()
+		}),
+		Int(&indexOffset),
+	).Write(iw.w, iw.endian)
+}
+
+// IndexReader reads records from a stream written by IndexWriter, using the index in the trailer to locate them.
+type IndexReader struct {
+	r       io.ReadSeeker
+	endian  binary.ByteOrder
+	entries []IndexEntry
+}
+
+// NewIndexReader creates an IndexReader by seeking to the end of r, and reading the footer and index.
+// ErrSizeMismatch is returned if the count of entries doesn't match the space between the index offset and the footer.
+func NewIndexReader(r io.ReadSeeker, endian binary.ByteOrder) (*IndexReader, error) {
+	end, err := r.Seek(-indexFooterSize, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	var indexOffset uint64
+	if err := Int(&indexOffset).Read(r, endian); err != nil {
+		return nil, err
+	}
+	if indexOffset > uint64(end) {
+		return nil, fmt.Errorf("%w: index offset %d is past the footer at offset %d", ErrOutOfBounds, indexOffset, end)
+	}
+	if _, err := r.Seek(int64(indexOffset), io.SeekStart); err != nil {
+		return nil, err
+	}
+	ir := &IndexReader{r: r, endian: endian}
+	var count uint32
+	lr := &io.LimitedReader{R: r, N: end - int64(indexOffset)}
+	if err := Int(&count).Read(lr, endian); err != nil {
+		return nil, err
+	}
+	// The count is checked against the bounded index size before anything is allocated for it.
+	if uint64(count)*indexEntrySize != uint64(lr.N) {
+		return nil, fmt.Errorf("%w: index of %d bytes can't hold %d entries", ErrSizeMismatch, lr.N, count)
+	}
+	if err := Slice(&ir.entries, count, func(e *IndexEntry) Mapper {
+		return e.mapper()
+	}).Read(lr, endian); err != nil {
+		return nil, err
+	}
+	for i, e := range ir.entries {
+		if e.Offset > indexOffset || e.Size > indexOffset-e.Offset {
+			return nil, fmt.Errorf("%w: record %d at offset %d with size %d overlaps the index", ErrOutOfBounds, i, e.Offset, e.Size)
+		}
+	}
+	return ir, nil
+}
+
+// NewIndexReaderAt creates an IndexReader over the first size bytes of ra, which allows records to be read from a random access source, like a memory-mapped file, without a shared stream position.
+func NewIndexReaderAt(ra io.ReaderAt, size int64, endian binary.ByteOrder) (*IndexReader, error) {
+	return NewIndexReader(io.NewSectionReader(ra, 0, size), endian)
+}
+
+// Len returns the number of records in the index.
+func (ir *IndexReader) Len() int {
+	return len(ir.entries)
+}
+
+// Entries returns the index entries.
+func (ir *IndexReader) Entries() []IndexEntry {
+	return ir.entries
+}
+
+// ReadRecord reads the record at index i with m.
+// The record is bounded to its size in the index, and ErrSizeMismatch is returned if m doesn't read the whole record.
+func (ir *IndexReader) ReadRecord(i int, m Mapper) error {
+	if i < 0 || i >= len(ir.entries) {
+		return fmt.Errorf("%w: record %d of %d", ErrOutOfBounds, i, len(ir.entries))
+	}
+	e := ir.entries[i]
+	if _, err := ir.r.Seek(int64(e.Offset), io.SeekStart); err != nil {
+		return err
+	}
+	lr := &io.LimitedReader{R: ir.r, N: int64(e.Size)}
+	if err := m.Read(lr, ir.endian); err != nil {
+		return err
+	}
+	if lr.N != 0 {
+		return fmt.Errorf("%w: record %d is %d bytes, but only %d were read", ErrSizeMismatch, i, e.Size, int64(e.Size)-lr.N)
+	}
+	return nil
+}
+
+// TrailerIndex maps a container of records that is located with a fixed-size end record, like the end of central directory record in a ZIP file.
+// The layout is each record, followed by an index entry with the offset and size of each record, followed by the end record.
+// The end record is the signature, then the uint32 count of records, the uint64 offset of the index, and the uint64 size of the index.
+// Offsets are relative to the start of the container.
+//
+// On read, the io.Reader must be an io.ReadSeeker positioned at the start of the container, and the container must extend to the end of the stream.
+// An io.ReaderAt with a Size method, like a memory-mapped file, may also be used, in which case the container starts at offset 0.
+// The end record is read first, which locates the index, which is then used to read each record at its offset.
+// Each record is bounded to its size in the index, and ErrSizeMismatch is returned if a record isn't read completely.
+// ErrBadMagic is returned if the signature doesn't match, and ErrOutOfBounds is returned if the index or a record is not within the container.
+// ErrSizeMismatch is returned if the count of records doesn't match the size of the index, which is checked before any entries are allocated.
+//
+// On write, the records are written sequentially, followed by the index and end record with the offsets and sizes that were recorded as they were written.
+func TrailerIndex[E any](records *[]E, signature []byte, mapVal func(*E) Mapper) Mapper {
+	if records == nil || mapVal == nil {
+		return nilMapping
+	}
+	endRecord := func(count *uint32, indexOffset, indexSize *uint64) Mapper {
+		return MapSequence(Magic(signature), Int(count), Int(indexOffset), Int(indexSize))
+	}
+	endRecordSize := int64(len(signature) + 20)
+	entryMapper := func(e *IndexEntry) Mapper {
+		return e.mapper()
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			rs := randomAccess(r)
+			if rs == nil {
+				return ErrSeekRequired
+			}
+			start, err := rs.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return err
+			}
+			end, err := rs.Seek(-endRecordSize, io.SeekEnd)
+			if err != nil {
+				return err
+			}
+			if end < start {
+				return fmt.Errorf("%w: container is too small for the end record", ErrOutOfBounds)
+			}
+			var (
+				count       uint32
+				indexOffset uint64
+				indexSize   uint64
+				entries     []IndexEntry
+			)
+			if err := endRecord(&count, &indexOffset, &indexSize).Read(rs, endian); err != nil {
+				return err
+			}
+			indexLimit := uint64(end - start)
+			if indexOffset > indexLimit || indexSize > indexLimit-indexOffset {
+				return fmt.Errorf("%w: index at offset %d with size %d is not within the container", ErrOutOfBounds, indexOffset, indexSize)
+			}
+			// The count is checked against the bounded index size before anything is allocated for it.
+			if uint64(count)*indexEntrySize != indexSize {
+				return fmt.Errorf("%w: index of %d bytes can't hold %d entries", ErrSizeMismatch, indexSize, count)
+			}
+			if _, err := rs.Seek(start+int64(indexOffset), io.SeekStart); err != nil {
+				return err
+			}
+			if err := ExactSize(Slice(&entries, count, entryMapper), int64(indexSize)).Read(&io.LimitedReader{R: rs, N: int64(indexSize)}, endian); err != nil {
+				return err
+			}
+			output := make([]E, count)
+			for i, e := range entries {
+				if e.Offset > indexOffset || e.Size > indexOffset-e.Offset {
+					return fmt.Errorf("%w: record %d at offset %d with size %d overlaps the index", ErrOutOfBounds, i, e.Offset, e.Size)
+				}
+				if _, err := rs.Seek(start+int64(e.Offset), io.SeekStart); err != nil {
+					return err
+				}
+				if err := ExactSize(mapVal(&output[i]), int64(e.Size)).Read(&io.LimitedReader{R: rs, N: int64(e.Size)}, endian); err != nil {
+					return err
+				}
+			}
+			*records = output
+			_, err = rs.Seek(end+endRecordSize, io.SeekStart)
+			return err
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			recordCount, err := toSize[uint32](len(*records))
+			if err != nil {
+				return err
+			}
+			cw := &countingWriter{writer: w}
+			entries := make([]IndexEntry, 0, len(*records))
+			for i := range *records {
+				offset := cw.n
+				if err := mapVal(&(*records)[i]).Write(cw, endian); err != nil {
+					return err
+				}
+				entries = append(entries, IndexEntry{Offset: uint64(offset), Size: uint64(cw.n - offset)})
+			}
+			indexOffset := uint64(cw.n)
+			if err := Slice(&entries, recordCount, entryMapper).Write(cw, endian); err != nil {
+				return err
+			}
+			indexSize := uint64(cw.n) - indexOffset
+			return endRecord(&recordCount, &indexOffset, &indexSize).Write(w, endian)
+		},
+	)
+}
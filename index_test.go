@@ -0,0 +1,119 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIndexWriter(t *testing.T) {
+	var (
+		endian = binary.LittleEndian
+		ws     = new(memWriteSeeker)
+		names  = []string{"first", "second", "third"}
+	)
+	iw := NewIndexWriter(ws, endian)
+	for i := range names {
+		assert.NoError(t, iw.WriteRecord(NullTermString(&names[i])))
+	}
+	assert.NoError(t, iw.Close())
+	assert.ErrorIs(t, iw.WriteRecord(NullTermString(&names[0])), ErrIndexClosed)
+	assert.Equal(t, []IndexEntry{{0, 6}, {6, 7}, {13, 6}}, iw.Entries())
+
+	ir, err := NewIndexReader(bytes.NewReader(ws.buf), endian)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, ir.Len())
+	for _, i := range []int{2, 0, 1} {
+		var name string
+		assert.NoError(t, ir.ReadRecord(i, NullTermString(&name)))
+		assert.Equal(t, names[i], name)
+	}
+	var val uint8
+	assert.ErrorIs(t, ir.ReadRecord(0, Byte(&val)), ErrSizeMismatch)
+	assert.ErrorIs(t, ir.ReadRecord(3, Byte(&val)), ErrOutOfBounds)
+
+	ir, err = NewIndexReaderAt(&readerAtOnly{data: ws.buf}, int64(len(ws.buf)), endian)
+	assert.NoError(t, err)
+	for i := range names {
+		var name string
+		assert.NoError(t, ir.ReadRecord(i, NullTermString(&name)))
+		assert.Equal(t, names[i], name)
+	}
+
+	corrupt := append([]byte{}, ws.buf...)
+	corrupt[len(corrupt)-8] = 0xFF
+	_, err = NewIndexReader(bytes.NewReader(corrupt), endian)
+	assert.ErrorIs(t, err, ErrOutOfBounds)
+
+	hugeCount := append([]byte{}, ws.buf...)
+	indexOffset := endian.Uint64(hugeCount[len(hugeCount)-8:])
+	endian.PutUint32(hugeCount[indexOffset:], 0xFFFFFFFF)
+	_, err = NewIndexReader(bytes.NewReader(hugeCount), endian)
+	assert.ErrorIs(t, err, ErrSizeMismatch)
+	_, err = NewIndexReaderAt(&readerAtOnly{data: hugeCount}, int64(len(hugeCount)), endian)
+	assert.ErrorIs(t, err, ErrSizeMismatch)
+}
+
+func TestTrailerIndex(t *testing.T) {
+	type entry struct {
+		name string
+		data []byte
+		size uint16
+	}
+	var (
+		buf     bytes.Buffer
+		endian  = binary.LittleEndian
+		records = []entry{
+			{name: "a.txt", data: []byte("hello")},
+			{name: "b.bin", data: []byte{1, 2, 3}},
+		}
+	)
+	for i := range records {
+		records[i].size = uint16(len(records[i].data))
+	}
+	m := TrailerIndex(&records, []byte("EOCD"), func(e *entry) Mapper {
+		return MapSequence(NullTermString(&e.name), LenBytes(&e.data, &e.size))
+	})
+	buf.Write([]byte("prefix"))
+	assert.NoError(t, m.Write(&buf, endian))
+	data := buf.Bytes()
+	assert.Equal(t, []byte("EOCD"), data[len(data)-24:len(data)-20])
+	assert.Equal(t, []byte{2, 0, 0, 0}, data[len(data)-20:len(data)-16], "Record count")
+	assert.Equal(t, []byte{24, 0, 0, 0, 0, 0, 0, 0}, data[len(data)-16:len(data)-8], "Index offset")
+	assert.Equal(t, []byte{32, 0, 0, 0, 0, 0, 0, 0}, data[len(data)-8:], "Index size")
+
+	expected := records
+	records = nil
+	r := bytes.NewReader(data)
+	_, _ = r.Seek(6, 0)
+	assert.NoError(t, m.Read(r, endian))
+	assert.Equal(t, expected, records)
+	assert.Equal(t, 0, r.Len(), "The whole container should be consumed")
+
+	corrupt := append([]byte{}, data...)
+	corrupt[len(corrupt)-16] = 0xFF
+	r = bytes.NewReader(corrupt)
+	_, _ = r.Seek(6, 0)
+	assert.ErrorIs(t, m.Read(r, endian), ErrOutOfBounds)
+
+	corrupt = append([]byte{}, data...)
+	corrupt[len(corrupt)-24] = 'X'
+	r = bytes.NewReader(corrupt)
+	_, _ = r.Seek(6, 0)
+	assert.ErrorIs(t, m.Read(r, endian), ErrBadMagic)
+
+	corrupt = append([]byte{}, data...)
+	copy(corrupt[len(corrupt)-20:], []byte{0xFF, 0xFF, 0xFF, 0xFF})
+	r = bytes.NewReader(corrupt)
+	_, _ = r.Seek(6, 0)
+	assert.ErrorIs(t, m.Read(r, endian), ErrSizeMismatch, "A huge count should be rejected before it's allocated")
+
+	records = nil
+	r = bytes.NewReader(data)
+	_, _ = r.Seek(6, 0)
+	assert.NoError(t, m.Read(r, endian), "The mapper should be reusable after a failed read")
+	assert.Equal(t, expected, records)
+
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrSeekRequired)
+}
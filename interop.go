@@ -0,0 +1,149 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrUnknownFraming = errors.New("unknown text framing")
+)
+
+// TextFraming specifies how the end of a text encoded region is determined.
+type TextFraming int
+
+const (
+	// LenPrefixedText prefixes the encoded text with its length in bytes as a uint32.
+	LenPrefixedText TextFraming = iota
+	// NewlineTerminatedText terminates the encoded text with a newline character.
+	// A carriage return preceding the newline will be ignored on read.
+	NewlineTerminatedText
+)
+
+// Base64 maps the output of m as base64 encoded text, using the given encoding and framing.
+// On read, the text is read and decoded in its entirety before being read with m.
+// ErrUnknownFraming is returned if framing is not one of the defined TextFraming values.
+func Base64(m Mapper, enc *base64.Encoding, framing TextFraming) Mapper {
+	if enc == nil {
+		return nilMapping
+	}
+	return textEncoded(m, framing, enc.EncodeToString, enc.DecodeString)
+}
+
+// Base32 is the same as Base64, except that it uses the given base32 encoding.
+func Base32(m Mapper, enc *base32.Encoding, framing TextFraming) Mapper {
+	if enc == nil {
+		return nilMapping
+	}
+	return textEncoded(m, framing, enc.EncodeToString, enc.DecodeString)
+}
+
+func textEncoded(m Mapper, framing TextFraming, encode func([]byte) string, decode func(string) ([]byte, error)) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	if framing != LenPrefixedText && framing != NewlineTerminatedText {
+		return errMapping(fmt.Errorf("%w: %d", ErrUnknownFraming, framing))
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var text []byte
+			switch framing {
+			case LenPrefixedText:
+				var length uint32
+				if err := Size(&length).Read(r, endian); err != nil {
+					return err
+				}
+				var buf bytes.Buffer
+				if _, err := io.CopyN(&buf, r, int64(length)); err != nil {
+					if errors.Is(err, io.EOF) {
+						return io.ErrUnexpectedEOF
+					}
+					return err
+				}
+				text = buf.Bytes()
+			case NewlineTerminatedText:
+				ubr := &unbufferedByteReader{reader: r}
+				for {
+					b, err := ubr.ReadByte()
+					if err != nil {
+						if errors.Is(err, io.EOF) && len(text) > 0 {
+							return io.ErrUnexpectedEOF
+						}
+						return err
+					}
+					if b == '\n' {
+						break
+					}
+					text = append(text, b)
+				}
+				text = bytes.TrimSuffix(text, []byte{'\r'})
+			}
+			decoded, err := decode(string(text))
+			if err != nil {
+				return err
+			}
+			return m.Read(bytes.NewReader(decoded), endian)
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			if err := m.Write(&buf, endian); err != nil {
+				return err
+			}
+			text := encode(buf.Bytes())
+			switch framing {
+			case LenPrefixedText:
+				length, err := toSize[uint32](len(text))
+				if err != nil {
+					return err
+				}
+				if err := Size(&length).Write(w, endian); err != nil {
+					return err
+				}
+				_, err = io.WriteString(w, text)
+				return err
+			default:
+				_, err := io.WriteString(w, text+"\n")
+				return err
+			}
+		},
+	)
+}
+
+// MsgPack maps target as a MessagePack encoded region, which is prefixed with its encoded size in bytes with the size type of length.
+// This is intended for the case where a mostly custom binary format embeds a MessagePack value.
+// The MessagePack codec is built in, so no additional dependencies are needed.
+// It supports booleans, integers, floats, strings, byte slices (as bin), slices, arrays, maps, pointers, and structs.
+// Structs are encoded as maps of their exported field names, which may be overridden with a `msgpack:"name"` tag, or skipped with `msgpack:"-"`.
+// Extension types, including the timestamp extension, are not supported, and ErrUnsupportedMsgPack is returned if one is read.
+// On read, ErrInvalidMsgPack is returned if the region isn't exactly one valid MessagePack value.
+// On write, length is set to the size of the encoded region.
+func MsgPack[T any, S SizeType](target *T, length *S) Mapper {
+	if target == nil || length == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var buf []byte
+			if err := LenBytes(&buf, length).Read(r, endian); err != nil {
+				return err
+			}
+			return msgpackUnmarshal(buf, target)
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			buf, err := msgpackMarshal(*target)
+			if err != nil {
+				return err
+			}
+			if *length, err = toSize[S](len(buf)); err != nil {
+				return err
+			}
+			return LenBytes(&buf, length).Write(w, endian)
+		},
+	)
+}
@@ -0,0 +1,75 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBase64(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		val    = uint32(0xDEADBEEF)
+		after  = uint8(7)
+	)
+	m := MapSequence(
+		Base64(Int(&val), base64.StdEncoding, LenPrefixedText),
+		Base64(Int(&val), base64.RawURLEncoding, NewlineTerminatedText),
+		Int(&after),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "\x00\x00\x00\x083q2+7w==3q2-7w\n\x07", buf.String())
+
+	val, after = 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint32(0xDEADBEEF), val)
+	assert.Equal(t, uint8(7), after)
+
+	buf.Reset()
+	buf.WriteString("3q2-7w\r\n")
+	assert.NoError(t, Base64(Int(&val), base64.RawURLEncoding, NewlineTerminatedText).Read(&buf, endian))
+	assert.Equal(t, uint32(0xDEADBEEF), val)
+
+	assert.ErrorIs(t, Base64(Int(&val), base64.StdEncoding, TextFraming(-1)).Write(&buf, endian), ErrUnknownFraming)
+}
+
+func TestBase32(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		val    = uint32(0xDEADBEEF)
+	)
+	m := Base32(Int(&val), base32.StdEncoding, NewlineTerminatedText)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "32W353Y=\n", buf.String())
+
+	val = 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint32(0xDEADBEEF), val)
+}
+
+func TestMsgPack(t *testing.T) {
+	type point struct {
+		X, Y int32
+	}
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		val    = point{X: 1, Y: -1}
+		length uint16
+		after  = uint8(7)
+	)
+	m := MapSequence(MsgPack(&val, &length), Int(&after))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint16(7), length)
+	assert.Equal(t, []byte{0x00, 0x07, 0x82, 0xA1, 'X', 0x01, 0xA1, 'Y', 0xFF, 0x07}, buf.Bytes())
+
+	val, length, after = point{}, 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, point{X: 1, Y: -1}, val)
+	assert.Equal(t, uint8(7), after)
+}
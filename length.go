@@ -0,0 +1,139 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	ErrExceedsParent = errors.New("declared length exceeds the remaining length of the enclosing region")
+)
+
+// limitTo bounds r to n bytes for a length-delimited region.
+// If r is already bounded by an enclosing region, then ErrExceedsParent is returned if n is larger than the enclosing region's remaining length.
+// This ensures that nested regions compose, so a corrupt inner length is reported before anything is read, rather than as an unexpected EOF partway through.
+func limitTo(r io.Reader, n uint64) (*io.LimitedReader, error) {
+	if n > math.MaxInt64 {
+		return nil, fmt.Errorf("%w: length %d", ErrSizeOverflow, n)
+	}
+	for parent := r; parent != nil; {
+		switch v := parent.(type) {
+		case *io.LimitedReader:
+			if int64(n) > v.N {
+				return nil, fmt.Errorf("%w: declared length is %d, but only %d bytes remain", ErrExceedsParent, n, v.N)
+			}
+			parent = nil
+		case *countingReader:
+			parent = v.reader
+		case *annotatingReader:
+			parent = v.reader
+		case *budgetReader:
+			parent = v.reader
+		default:
+			parent = nil
+		}
+	}
+	return &io.LimitedReader{R: r, N: int64(n)}, nil
+}
+
+// ConsistentLen maps m as a region prefixed with its length in bytes.
+// On read, m is bounded to the declared length, and ErrSizeMismatch is returned if m doesn't consume exactly that many bytes, or tries to read past them.
+// When nested within another length-delimited region, ErrExceedsParent is returned if the declared length is larger than the enclosing region allows.
+// On write, the output of m is buffered to measure its length, which is set in length and written before the buffered data.
+func ConsistentLen[S SizeType](length *S, m Mapper) Mapper {
+	if length == nil || m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Size(length).Read(r, endian); err != nil {
+				return err
+			}
+			lr, err := limitTo(r, uint64(*length))
+			if err != nil {
+				return err
+			}
+			if err := m.Read(lr, endian); err != nil {
+				// Running out of bytes with the whole region consumed means m tried to read past the declared length, rather than the input being truncated.
+				if lr.N == 0 && (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) {
+					return fmt.Errorf("%w: read past the declared length of %d bytes", ErrSizeMismatch, *length)
+				}
+				return err
+			}
+			if lr.N != 0 {
+				return fmt.Errorf("%w: declared length is %d, but only %d bytes were read", ErrSizeMismatch, *length, int64(*length)-lr.N)
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			if err := m.Write(&buf, endian); err != nil {
+				return err
+			}
+			size, err := toSize[S](buf.Len())
+			if err != nil {
+				return err
+			}
+			*length = size
+			if err := Size(length).Write(w, endian); err != nil {
+				return err
+			}
+			_, err = buf.WriteTo(w)
+			return err
+		},
+	)
+}
+
+// Record maps m as a record prefixed with its total length in bytes.
+// If inclusive is true, then the length includes the size of the length field itself, otherwise it only includes the bytes mapped by m.
+// On read, m is bounded to the record's length, and any bytes that m doesn't read are skipped as slack space.
+// ErrSizeMismatch is returned if an inclusive length is smaller than the length field itself, and ErrExceedsParent is returned if the length is larger than an enclosing length-delimited region allows.
+// On write, the output of m is buffered to measure its length, which is set in totalLen and written before the buffered data.
+func Record[S SizeType](totalLen *S, inclusive bool, m Mapper) Mapper {
+	if totalLen == nil || m == nil {
+		return nilMapping
+	}
+	var overhead int
+	if inclusive {
+		overhead = binary.Size(*totalLen)
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Size(totalLen).Read(r, endian); err != nil {
+				return err
+			}
+			if uint64(*totalLen) < uint64(overhead) {
+				return fmt.Errorf("%w: record length %d is less than the size of the length field", ErrSizeMismatch, *totalLen)
+			}
+			lr, err := limitTo(r, uint64(*totalLen)-uint64(overhead))
+			if err != nil {
+				return err
+			}
+			if err := m.Read(lr, endian); err != nil {
+				return err
+			}
+			_, err = io.Copy(io.Discard, lr)
+			return err
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			if err := m.Write(&buf, endian); err != nil {
+				return err
+			}
+			size, err := toSize[S](buf.Len() + overhead)
+			if err != nil {
+				return err
+			}
+			*totalLen = size
+			if err := Size(totalLen).Write(w, endian); err != nil {
+				return err
+			}
+			_, err = buf.WriteTo(w)
+			return err
+		},
+	)
+}
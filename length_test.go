@@ -0,0 +1,112 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestConsistentLen(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		length uint8
+		name   = "binmap"
+		val    = uint16(5)
+	)
+	m := MapSequence(
+		ConsistentLen(&length, NullTermString(&name)),
+		Int(&val),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint8(7), length)
+	assert.Equal(t, 10, buf.Len())
+
+	name, val, length = "", 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, "binmap", name)
+	assert.Equal(t, uint16(5), val)
+
+	buf.Reset()
+	buf.Write([]byte{8, 'b', 'i', 'n', 0, 'x', 'x', 'x', 'x'})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrSizeMismatch, "Under-reads should be reported")
+
+	buf.Reset()
+	buf.Write([]byte{3, 'b', 'i', 'n', 0, 0, 5})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrSizeMismatch, "Over-reads should be prevented")
+
+	buf.Reset()
+	buf.Write([]byte{8, 'b', 'i', 'n'})
+	assert.ErrorIs(t, m.Read(&buf, endian), io.EOF, "Truncated input should be distinguishable from a length mismatch")
+}
+
+func TestRecord(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		length uint32
+		val    = uint16(5)
+	)
+	m := Record(&length, true, Int(&val))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint32(6), length)
+	assert.Equal(t, []byte{0, 0, 0, 6, 0, 5}, buf.Bytes())
+
+	m = Record(&length, false, Int(&val))
+	buf.Reset()
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint32(2), length)
+	assert.Equal(t, []byte{0, 0, 0, 2, 0, 5}, buf.Bytes())
+
+	// Records with slack space
+	buf.Reset()
+	buf.Write([]byte{0, 0, 0, 8, 0, 1, 0xFF, 0xFF})
+	buf.Write([]byte{0, 0, 0, 4, 0, 2, 0xFF, 0xFF})
+	m = MapSequence(Record(&length, true, Int(&val)), Record(&length, false, Int(&val)))
+	val = 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(2), val)
+	assert.Equal(t, 0, buf.Len())
+
+	buf.Write([]byte{0, 0, 0, 5, 0, 1})
+	assert.ErrorIs(t, Record(&length, true, Int(&val)).Read(&buf, endian), io.ErrUnexpectedEOF, "Reading past the record should fail")
+	buf.Reset()
+	buf.Write([]byte{0, 0, 0, 3, 0, 1})
+	assert.ErrorIs(t, Record(&length, true, Int(&val)).Read(&buf, endian), ErrSizeMismatch)
+}
+
+func TestNestedLengths(t *testing.T) {
+	var (
+		buf                  bytes.Buffer
+		endian               = binary.BigEndian
+		outer, middle, inner uint8
+		outerTag, middleTag  byte
+		payload              []byte
+		payloadLen           uint8
+	)
+	m := ConsistentLen(&outer, MapSequence(
+		Byte(&outerTag),
+		Record(&middle, false, MapSequence(
+			Byte(&middleTag),
+			ConsistentLen(&inner, LenBytes(&payload, &payloadLen)),
+		)),
+	))
+	outerTag, middleTag, payload, payloadLen = 1, 2, []byte("abc"), 3
+	assert.NoError(t, m.Write(&buf, endian))
+	data := buf.Bytes()
+	assert.Equal(t, []byte{8, 1, 6, 2, 4, 3, 'a', 'b', 'c'}, data)
+
+	payload = nil
+	assert.NoError(t, m.Read(bytes.NewReader(data), endian))
+	assert.Equal(t, "abc", string(payload))
+
+	corrupt := append([]byte{}, data...)
+	corrupt[4] = 200
+	assert.ErrorIs(t, m.Read(bytes.NewReader(append(corrupt, make([]byte, 200)...)), endian), ErrExceedsParent, "The innermost length must not exceed its parent, even if the stream has more data")
+
+	corrupt = append([]byte{}, data...)
+	corrupt[2] = 7
+	assert.ErrorIs(t, m.Read(bytes.NewReader(append(corrupt, 0)), endian), ErrExceedsParent)
+}
@@ -0,0 +1,176 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrBadMagic       = errors.New("magic signature mismatch")
+	ErrFillMismatch   = errors.New("unexpected byte in fill region")
+	ErrTableMismatch  = errors.New("embedded table does not match the expected table")
+	ErrUnknownVersion = errors.New("unknown format version")
+	ErrMaskLength     = errors.New("mask length does not match signature length")
+	ErrNilVersion     = errors.New("nil body mapper for format version")
+)
+
+// Magic maps a fixed signature, such as those used to identify a file format.
+// On read, ErrBadMagic is returned if the bytes read don't exactly match the signature.
+// On write, the signature is written.
+func Magic(signature []byte) Mapper {
+	mask := make([]byte, len(signature))
+	for i := range mask {
+		mask[i] = 0xFF
+	}
+	return MagicMask(signature, mask)
+}
+
+// MagicMask is the same as Magic, except that only the bits set in mask are compared on read.
+// This allows a signature to have "don't care" positions, such as an embedded version byte.
+// A mask byte of 0xFF requires an exact match, and a mask byte of 0x00 ignores that position entirely.
+// On write, the signature is written as-is.
+// ErrMaskLength is returned if signature and mask are different lengths.
+func MagicMask(signature, mask []byte) Mapper {
+	if len(signature) != len(mask) {
+		return errMapping(fmt.Errorf("%w: signature length %d, mask length %d", ErrMaskLength, len(signature), len(mask)))
+	}
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			buf := make([]byte, len(signature))
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return err
+			}
+			for i := range buf {
+				if buf[i]&mask[i] != signature[i]&mask[i] {
+					return fmt.Errorf("%w: expected 0x%02X at offset %d, but found 0x%02X", ErrBadMagic, signature[i], i, buf[i])
+				}
+			}
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			_, err := w.Write(signature)
+			return err
+		},
+	)
+}
+
+const (
+	fillChunkSize = 4096
+)
+
+// ConstantFill maps a region of length bytes that must all be equal to value, such as a reserved or padding region.
+// On read, the region is consumed in chunks without retaining it, and ErrFillMismatch is returned with the offset of the first byte that doesn't match.
+// On write, length copies of value are written.
+func ConstantFill(length int, value byte) Mapper {
+	chunk := length
+	if chunk > fillChunkSize {
+		chunk = fillChunkSize
+	}
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			buf := make([]byte, chunk)
+			for offset := 0; offset < length; {
+				n := length - offset
+				if n > len(buf) {
+					n = len(buf)
+				}
+				if _, err := io.ReadFull(r, buf[:n]); err != nil {
+					return err
+				}
+				for i, b := range buf[:n] {
+					if b != value {
+						return fmt.Errorf("%w: expected 0x%02X at offset %d, but found 0x%02X", ErrFillMismatch, value, offset+i, b)
+					}
+				}
+				offset += n
+			}
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			buf := bytes.Repeat([]byte{value}, chunk)
+			for remaining := length; remaining > 0; {
+				n := remaining
+				if n > len(buf) {
+					n = len(buf)
+				}
+				if _, err := w.Write(buf[:n]); err != nil {
+					return err
+				}
+				remaining -= n
+			}
+			return nil
+		},
+	)
+}
+
+// KnownTable maps an embedded table of constants, such as a precomputed CRC table, that must match the expected table.
+// On read, len(expected) elements are read with the Mapper returned from mapVal, and ErrTableMismatch is returned with the index of the first element that doesn't match.
+// The table is only set in target if it matches.
+// On write, expected is written regardless of the contents of target.
+func KnownTable[T comparable](target *[]T, expected []T, mapVal func(*T) Mapper) Mapper {
+	if target == nil || mapVal == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			table := make([]T, len(expected))
+			for i := range table {
+				if err := mapVal(&table[i]).Read(r, endian); err != nil {
+					return err
+				}
+				if table[i] != expected[i] {
+					return fmt.Errorf("%w: expected %v at index %d, but found %v", ErrTableMismatch, expected[i], i, table[i])
+				}
+			}
+			*target = table
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			for i := range expected {
+				val := expected[i]
+				if err := mapVal(&val).Write(w, endian); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// FormatHeader maps the standard preamble of a file format, which is a magic signature followed by a uint16 version, and then the body for that version.
+// On read, ErrBadMagic is returned if the signature doesn't match, and ErrUnknownVersion is returned if there is no body Mapper for the version read into version.
+// On write, the signature and version are written, followed by the body Mapper for that version.
+// ErrUnknownVersion is returned before anything is written if there is no body Mapper for the version.
+// ErrNilVersion is returned if any version has a nil body Mapper.
+func FormatHeader(magic []byte, version *uint16, versions map[uint16]Mapper) Mapper {
+	if version == nil {
+		return nilMapping
+	}
+	for v, body := range versions {
+		if body == nil {
+			return errMapping(fmt.Errorf("%w: %d", ErrNilVersion, v))
+		}
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if err := MapSequence(Magic(magic), Int(version)).Read(r, endian); err != nil {
+				return err
+			}
+			body, ok := versions[*version]
+			if !ok {
+				return fmt.Errorf("%w: %d", ErrUnknownVersion, *version)
+			}
+			return body.Read(r, endian)
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			body, ok := versions[*version]
+			if !ok {
+				return fmt.Errorf("%w: %d", ErrUnknownVersion, *version)
+			}
+			return MapSequence(Magic(magic), Int(version), body).Write(w, endian)
+		},
+	)
+}
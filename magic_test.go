@@ -0,0 +1,108 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMagic(t *testing.T) {
+	var buf bytes.Buffer
+	m := Magic([]byte("BMAP"))
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, "BMAP", buf.String())
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+
+	buf.WriteString("BMAQ")
+	assert.ErrorIs(t, m.Read(&buf, binary.BigEndian), ErrBadMagic)
+}
+
+func TestMagicMask(t *testing.T) {
+	var buf bytes.Buffer
+	m := MagicMask([]byte{'B', 'M', 0x01, 'P'}, []byte{0xFF, 0xFF, 0x00, 0xFF})
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, []byte{'B', 'M', 0x01, 'P'}, buf.Bytes())
+
+	buf.Reset()
+	buf.Write([]byte{'B', 'M', 0x07, 'P'})
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+
+	buf.Write([]byte{'B', 'N', 0x07, 'P'})
+	assert.ErrorIs(t, m.Read(&buf, binary.BigEndian), ErrBadMagic)
+
+	assert.ErrorIs(t, MagicMask([]byte{1, 2}, []byte{0xFF}).Read(&buf, binary.BigEndian), ErrMaskLength)
+}
+
+func TestConstantFill(t *testing.T) {
+	var buf bytes.Buffer
+	m := ConstantFill(10_000, 0xCC)
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, bytes.Repeat([]byte{0xCC}, 10_000), buf.Bytes())
+
+	out := buf.Bytes()
+	assert.NoError(t, m.Read(bytes.NewReader(out), binary.BigEndian))
+
+	out[5000] = 0
+	err := m.Read(bytes.NewReader(out), binary.BigEndian)
+	assert.ErrorIs(t, err, ErrFillMismatch)
+	assert.Contains(t, err.Error(), "offset 5000")
+}
+
+func TestKnownTable(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		endian   = binary.BigEndian
+		expected = []uint16{0x0000, 0x1021, 0x2042, 0x3063}
+		table    = []uint16{9, 9}
+	)
+	m := KnownTable(&table, expected, Int[uint16])
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0x10, 0x21, 0x20, 0x42, 0x30, 0x63}, buf.Bytes(), "The expected table should be written regardless of the target")
+	data := append([]byte{}, buf.Bytes()...)
+
+	table = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, expected, table)
+
+	data[5] = 0x43
+	table = nil
+	err := m.Read(bytes.NewReader(data), endian)
+	assert.ErrorIs(t, err, ErrTableMismatch)
+	assert.Contains(t, err.Error(), "index 2")
+	assert.Nil(t, table)
+}
+
+func TestFormatHeader(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		endian  = binary.BigEndian
+		version = uint16(2)
+		count   uint8
+		size    uint32
+	)
+	m := FormatHeader([]byte("BMAP"), &version, map[uint16]Mapper{
+		1: Int(&count),
+		2: MapSequence(Int(&count), Int(&size)),
+	})
+	count, size = 3, 100
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{'B', 'M', 'A', 'P', 0, 2, 3, 0, 0, 0, 100}, buf.Bytes())
+
+	version, count, size = 0, 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(2), version)
+	assert.Equal(t, uint8(3), count)
+	assert.Equal(t, uint32(100), size)
+
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{'B', 'M', 'A', 'P', 0, 3}), endian), ErrUnknownVersion)
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{'B', 'M', 'A', 'Q', 0, 1}), endian), ErrBadMagic)
+
+	buf.Reset()
+	version = 3
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrUnknownVersion)
+	assert.Zero(t, buf.Len(), "Nothing should be written for an unknown version")
+
+	m = FormatHeader([]byte("BMAP"), &version, map[uint16]Mapper{1: Int(&count), 2: nil})
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{'B', 'M', 'A', 'P', 0, 2}), endian), ErrNilVersion)
+}
@@ -1,6 +1,7 @@
 package bin
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -9,8 +10,17 @@ import (
 )
 
 var (
-	ErrNilReadWrite = errors.New("nil read source or write target")
-	ErrPanic        = errors.New("panic during Read or Write")
+	ErrNilReadWrite      = errors.New("nil read source or write target")
+	ErrPanic             = errors.New("panic during Read or Write")
+	ErrReadNotSupported  = errors.New("read is not supported")
+	ErrWriteNotSupported = errors.New("write is not supported")
+	ErrNoVariant         = errors.New("no active variant")
+	ErrManyVariants      = errors.New("multiple active variants")
+	ErrUnknownVariant    = errors.New("unknown variant discriminator")
+	ErrInvariant         = errors.New("invariant violated")
+	ErrPlaceholderRoot   = errors.New("placeholder must be written directly within a Transactional mapper")
+	ErrBitRange          = errors.New("bit is out of range for the flags word")
+	ErrNoRecoverable     = errors.New("at least one recoverable error must be given")
 )
 
 // ReadFunc is a function that reads data from a binary source.
@@ -55,6 +65,19 @@ var nilMapping = &mapper{
 	},
 }
 
+// errMapping creates a Mapper that always returns the given error.
+// This is useful for reporting invalid mapper configuration at Read/Write time.
+func errMapping(err error) Mapper {
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			return err
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			return err
+		},
+	}
+}
+
 // MapSequence creates a Mapper that uses each given Mapper in order.
 func MapSequence(mappings ...Mapper) Mapper {
 	return &mapper{
@@ -77,6 +100,250 @@ func MapSequence(mappings ...Mapper) Mapper {
 	}
 }
 
+// OneOfCase is a single alternative in a OneOf Mapper.
+type OneOfCase struct {
+	// IsActive reports whether this variant is the one that should be written.
+	IsActive func() bool
+	// Discriminator is written before the variant to identify it when reading.
+	Discriminator byte
+	// Mapper reads and writes the variant.
+	// Since the variant is only known at read time, this Mapper should handle allocating its target if necessary.
+	Mapper Mapper
+}
+
+// OneOf maps exactly one of several alternatives, identified by a leading discriminator byte.
+// On write, the single active variant will have its discriminator written, followed by the variant itself.
+// An error is returned if zero or multiple variants are active.
+// On read, the discriminator is read, and the matching variant's Mapper is used to read the rest.
+// If any variant has a nil IsActive function or Mapper, then ErrNilReadWrite is returned when mapped.
+func OneOf(variants ...OneOfCase) Mapper {
+	for _, v := range variants {
+		if v.IsActive == nil || v.Mapper == nil {
+			return nilMapping
+		}
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var disc byte
+			if err := binary.Read(r, endian, &disc); err != nil {
+				return err
+			}
+			for _, v := range variants {
+				if v.Discriminator == disc {
+					return v.Mapper.Read(r, endian)
+				}
+			}
+			return fmt.Errorf("%w: %d", ErrUnknownVariant, disc)
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			var active *OneOfCase
+			for i := range variants {
+				if !variants[i].IsActive() {
+					continue
+				}
+				if active != nil {
+					return fmt.Errorf("%w: discriminators %d and %d", ErrManyVariants, active.Discriminator, variants[i].Discriminator)
+				}
+				active = &variants[i]
+			}
+			if active == nil {
+				return ErrNoVariant
+			}
+			if err := binary.Write(w, endian, active.Discriminator); err != nil {
+				return err
+			}
+			return active.Mapper.Write(w, endian)
+		},
+	}
+}
+
+// HeaderDispatch maps a common header, and then uses the type returned from typeOf to select the body Mapper.
+// The header will be fully populated before typeOf is called, so it's available to the selected body Mapper.
+// ErrUnknownVariant is returned if there is no body Mapper for the header's type.
+func HeaderDispatch[H any, T comparable](header *H, headerMapper Mapper, typeOf func(*H) T, bodies map[T]Mapper) Mapper {
+	if header == nil || headerMapper == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if err := headerMapper.Read(r, endian); err != nil {
+				return err
+			}
+			typ := typeOf(header)
+			body, ok := bodies[typ]
+			if !ok {
+				return fmt.Errorf("%w: %v", ErrUnknownVariant, typ)
+			}
+			return body.Read(r, endian)
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			typ := typeOf(header)
+			body, ok := bodies[typ]
+			if !ok {
+				return fmt.Errorf("%w: %v", ErrUnknownVariant, typ)
+			}
+			if err := headerMapper.Write(w, endian); err != nil {
+				return err
+			}
+			return body.Write(w, endian)
+		},
+	}
+}
+
+// MaybeTrailing allows a trailing field to be absent from the source, as is the case with records written before the field was added.
+// If the source is at EOF when reading starts, then the target is left unchanged and no error is returned.
+// If EOF is reached after some bytes have been read, then this is considered a partial record and the error is returned.
+// Writing is passed through to m unchanged.
+func MaybeTrailing(m Mapper) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			cr := &countingReader{reader: r}
+			err := m.Read(cr, endian)
+			if errors.Is(err, io.EOF) && cr.n == 0 {
+				return nil
+			}
+			if errors.Is(err, io.EOF) {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		},
+		write: m.Write,
+	}
+}
+
+// Embed makes it explicit that the given Mapper for an embedded or nested struct should be mapped inline with the surrounding fields.
+// This is a pass-through, and the given Mapper is returned unchanged.
+// See EmbedPtr for embedded struct pointers that may be nil.
+func Embed(m Mapper) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return m
+}
+
+// EmbedPtr maps an embedded or nested struct pointer inline with the surrounding fields.
+// On read, a new T will be allocated if the pointer is nil, before being read with the Mapper returned from mapVal.
+// On write, a nil pointer will be written as the zero value of T, so the flattened layout is the same regardless of whether the pointer is nil.
+func EmbedPtr[T any](target **T, mapVal func(*T) Mapper) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if *target == nil {
+				*target = new(T)
+			}
+			return mapVal(*target).Read(r, endian)
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if *target == nil {
+				return mapVal(new(T)).Write(w, endian)
+			}
+			return mapVal(*target).Write(w, endian)
+		},
+	}
+}
+
+// Tracked maps a validity flag followed by a value slot that is always present, as is common with in-place updatable records.
+// On read, the flag is read into valid, and then the value is read with m. If the value is not valid, then target is set to its zero value.
+// On write, the flag is written, followed by the value. If the value is not valid, then the zero value of T is written in its place.
+func Tracked[T any](target *T, valid *bool, m Mapper) Mapper {
+	if target == nil || valid == nil || m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Bool(valid).Read(r, endian); err != nil {
+				return err
+			}
+			if err := m.Read(r, endian); err != nil {
+				return err
+			}
+			if !*valid {
+				var zero T
+				*target = zero
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			if err := Bool(valid).Write(w, endian); err != nil {
+				return err
+			}
+			if *valid {
+				return m.Write(w, endian)
+			}
+			val := *target
+			defer func() {
+				*target = val
+			}()
+			var zero T
+			*target = zero
+			return m.Write(w, endian)
+		},
+	)
+}
+
+// BitGated maps an optional field that is only present if the given bit of a flags word is set, as is common in formats with a flags word at the start of a record.
+// The flags must have been read before this Mapper is used, and bit is numbered from the least significant bit.
+// On write, m is only written if the bit is set, so it's the caller's responsibility to set the bit when the field should be present.
+// ErrBitRange is returned if bit is not within 0 through 31.
+func BitGated(flags *uint32, bit int, m Mapper) Mapper {
+	if flags == nil || m == nil {
+		return nilMapping
+	}
+	if bit < 0 || bit > 31 {
+		return errMapping(fmt.Errorf("%w: bit %d of a 32-bit flags word", ErrBitRange, bit))
+	}
+	mask := uint32(1) << bit
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if *flags&mask == 0 {
+				return nil
+			}
+			return m.Read(r, endian)
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if *flags&mask == 0 {
+				return nil
+			}
+			return m.Write(w, endian)
+		},
+	}
+}
+
+// OrDefault allows a malformed field to degrade to a default value rather than failing the whole read, which is useful for lenient parsing of best-effort metadata.
+// Since this can mask real corruption, only the errors given in recoverable, as matched with errors.Is, will be recovered, and at least one must be given, otherwise ErrNoRecoverable is returned.
+// On read, if m returns a recoverable error, then target is set to def and the error is discarded.
+// Note that any bytes consumed by the failed read are not restored, so m should be bounded to a known size, such as with PadTo.
+// Writing is passed through to m unchanged.
+func OrDefault[T any](target *T, def T, m Mapper, recoverable ...error) Mapper {
+	if target == nil || m == nil {
+		return nilMapping
+	}
+	if len(recoverable) == 0 {
+		return errMapping(ErrNoRecoverable)
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			err := m.Read(r, endian)
+			if err == nil {
+				return nil
+			}
+			for _, rec := range recoverable {
+				if errors.Is(err, rec) {
+					*target = def
+					return nil
+				}
+			}
+			return err
+		},
+		write: m.Write,
+	}
+}
+
 // Any is provided to make it easy to create a custom Mapper for any given type.
 func Any(read ReadFunc, write WriteFunc) Mapper {
 	return &mapper{
@@ -85,6 +352,22 @@ func Any(read ReadFunc, write WriteFunc) Mapper {
 	}
 }
 
+// ReadOnly creates a Mapper that only supports reading.
+// Writing with the returned Mapper will return ErrWriteNotSupported, so callers can distinguish a deliberately one-directional Mapper from a bug.
+func ReadOnly(read ReadFunc) Mapper {
+	return Any(read, func(w io.Writer, endian binary.ByteOrder) error {
+		return ErrWriteNotSupported
+	})
+}
+
+// WriteOnly creates a Mapper that only supports writing.
+// Reading with the returned Mapper will return ErrReadNotSupported, so callers can distinguish a deliberately one-directional Mapper from a bug.
+func WriteOnly(write WriteFunc) Mapper {
+	return Any(func(r io.Reader, endian binary.ByteOrder) error {
+		return ErrReadNotSupported
+	}, write)
+}
+
 // OverrideEndian will override the endian settings for a single operation.
 // This is useful for UTF-16 strings which are often read/written little-endian.
 func OverrideEndian(m Mapper, endian binary.ByteOrder) Mapper {
@@ -98,6 +381,35 @@ func OverrideEndian(m Mapper, endian binary.ByteOrder) Mapper {
 	)
 }
 
+// Endian will apply the given endian policy to a sequence of mappers.
+// This is the same as using OverrideEndian with MapSequence, and is useful for a block of fields with a different byte order than the rest of a format.
+func Endian(endian binary.ByteOrder, mappers ...Mapper) Mapper {
+	return OverrideEndian(MapSequence(mappers...), endian)
+}
+
+// DynamicEndian uses the endian policy held in endianField at the time of the Read or Write call for m.
+// This is useful for formats where an earlier field declares the byte order of the rest of the data, since endianField can be populated by an earlier mapper that decodes the marker.
+// If endianField holds nil, then the endian policy passed to Read or Write is used.
+func DynamicEndian(endianField *binary.ByteOrder, m Mapper) Mapper {
+	if endianField == nil || m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if *endianField != nil {
+				endian = *endianField
+			}
+			return m.Read(r, endian)
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			if *endianField != nil {
+				endian = *endianField
+			}
+			return m.Write(w, endian)
+		},
+	)
+}
+
 type BeforeReadHandler = func() error
 type AfterReadHandler = func(err error) error
 type BeforeWriteHandler = func() error
@@ -164,6 +476,59 @@ func ValidateRead(mapper Mapper, validator AfterReadHandler) Mapper {
 	})
 }
 
+// Invariant will run check after successfully reading with the mapper, to enforce cross-field constraints.
+// This is similar to ValidateRead, except that check is only called if the read succeeded.
+// Checks like ExactlyOne and RequiredIf may be used to express common constraints.
+func Invariant(mapper Mapper, check func() error) Mapper {
+	return ValidateRead(mapper, func(err error) error {
+		if err != nil {
+			return err
+		}
+		return check()
+	})
+}
+
+// nilCheck is an Invariant check for nil arguments, which fails in the same way as nilMapping.
+func nilCheck() error {
+	return ErrNilReadWrite
+}
+
+// ExactlyOne creates an Invariant check that returns ErrInvariant unless exactly one of the given flags is true.
+// ErrNilReadWrite is returned by the check if any of the flags are nil.
+func ExactlyOne(present ...*bool) func() error {
+	for _, p := range present {
+		if p == nil {
+			return nilCheck
+		}
+	}
+	return func() error {
+		count := 0
+		for _, p := range present {
+			if *p {
+				count++
+			}
+		}
+		if count != 1 {
+			return fmt.Errorf("%w: expected exactly one present field, but found %d", ErrInvariant, count)
+		}
+		return nil
+	}
+}
+
+// RequiredIf creates an Invariant check that returns ErrInvariant if cond is true, but required is false.
+// ErrNilReadWrite is returned by the check if either flag is nil.
+func RequiredIf(cond, required *bool) func() error {
+	if cond == nil || required == nil {
+		return nilCheck
+	}
+	return func() error {
+		if *cond && !*required {
+			return fmt.Errorf("%w: required field is not present", ErrInvariant)
+		}
+		return nil
+	}
+}
+
 // NormalizeWrite will run the normalizer before writing with the mapper.
 func NormalizeWrite(mapper Mapper, normalizer BeforeWriteHandler) Mapper {
 	return NewEventHandler(mapper, EventHandler{
@@ -171,6 +536,112 @@ func NormalizeWrite(mapper Mapper, normalizer BeforeWriteHandler) Mapper {
 	})
 }
 
+// Computed maps a field that is derived from other data, such as a count or total size.
+// On write, target is set to the result of compute before writing with m, so the written value is always consistent with its source data.
+// On read, m reads into target as normal.
+func Computed[T any](target *T, compute func() T, m Mapper) Mapper {
+	if target == nil || m == nil {
+		return nilMapping
+	}
+	return NormalizeWrite(m, func() error {
+		*target = compute()
+		return nil
+	})
+}
+
+// transactionBuffer holds the output of a Transactional write, along with any Placeholder patches to apply before it's flushed.
+// The parent is the io.Writer that the output will be flushed to, which allows state carried by enclosing writers, like a RecursionBudget, to be found through the buffer.
+type transactionBuffer struct {
+	bytes.Buffer
+	parent  io.Writer
+	patches []func() error
+}
+
+// findTransaction finds the transactionBuffer that w writes through to, if any.
+func findTransaction(w io.Writer) *transactionBuffer {
+	for {
+		switch v := w.(type) {
+		case *transactionBuffer:
+			return v
+		case *countingWriter:
+			w = v.writer
+		case *budgetWriter:
+			w = v.writer
+		default:
+			return nil
+		}
+	}
+}
+
+// Transactional buffers the entire write of m in memory, and only writes it to the io.Writer if m succeeds.
+// This ensures that an error partway through writing produces no output at all, at the cost of holding the whole output in memory.
+// Any Placeholder values written within m are patched before the output is written.
+// Reading is passed through to m unchanged.
+func Transactional(m Mapper) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return Any(
+		m.Read,
+		func(w io.Writer, endian binary.ByteOrder) error {
+			buf := &transactionBuffer{parent: w}
+			if err := m.Write(buf, endian); err != nil {
+				return err
+			}
+			for _, patch := range buf.patches {
+				if err := patch(); err != nil {
+					return err
+				}
+			}
+			_, err := buf.WriteTo(w)
+			return err
+		},
+	)
+}
+
+// Placeholder maps a field whose value may not be known until later in the same write, such as a header field that summarizes the body.
+// The returned function sets the value of target, and may be called at any point before the enclosing Transactional write completes.
+// On write, the current value of target is written with m, and it's written again in the same place with the final value of target before the Transactional output is flushed.
+// The Placeholder must be written within a Transactional Mapper, without any intermediate buffering, otherwise ErrPlaceholderRoot is returned.
+// ErrSizeMismatch is returned if the final value is not the same size as the initial value.
+// Reading is passed through to m unchanged.
+func Placeholder[T any](target *T, m Mapper) (Mapper, func(T)) {
+	set := func(val T) {
+		if target != nil {
+			*target = val
+		}
+	}
+	if target == nil || m == nil {
+		return nilMapping, set
+	}
+	return Any(
+		m.Read,
+		func(w io.Writer, endian binary.ByteOrder) error {
+			buf := findTransaction(w)
+			if buf == nil {
+				return ErrPlaceholderRoot
+			}
+			offset := buf.Len()
+			if err := m.Write(w, endian); err != nil {
+				return err
+			}
+			size := buf.Len() - offset
+			buf.patches = append(buf.patches, func() error {
+				var patched bytes.Buffer
+				if err := m.Write(&patched, endian); err != nil {
+					return err
+				}
+				if patched.Len() != size {
+					return fmt.Errorf("%w: placeholder was %d bytes, but the final value is %d bytes", ErrSizeMismatch, size, patched.Len())
+				}
+				copy(buf.Bytes()[offset:], patched.Bytes())
+				return nil
+			})
+			return nil
+		},
+	), set
+}
+
 // Lock will manage locking and unlocking a sync.Mutex before/after a read/write.
 func Lock(mapper Mapper, mux *sync.Mutex) Mapper {
 	return NewEventHandler(mapper, EventHandler{
@@ -2,11 +2,17 @@ package bin
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/binary"
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"hash/crc32"
 	"io"
+	"math/big"
+	"net/netip"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 func TestAny(t *testing.T) {
@@ -172,3 +178,656 @@ func TestOnPanic(t *testing.T) {
 		assert.ErrorIs(t, onPanic.Write(nil, nil), ErrPanic)
 	})
 }
+
+func TestOneOf(t *testing.T) {
+	type (
+		circle struct {
+			radius uint16
+		}
+		rect struct {
+			width, height uint16
+		}
+	)
+	var (
+		c   *circle
+		r   *rect
+		buf bytes.Buffer
+	)
+	m := OneOf(
+		OneOfCase{
+			IsActive:      func() bool { return c != nil },
+			Discriminator: 1,
+			Mapper: Any(
+				func(r io.Reader, endian binary.ByteOrder) error {
+					c = new(circle)
+					return Int(&c.radius).Read(r, endian)
+				},
+				func(w io.Writer, endian binary.ByteOrder) error {
+					return Int(&c.radius).Write(w, endian)
+				},
+			),
+		},
+		OneOfCase{
+			IsActive:      func() bool { return r != nil },
+			Discriminator: 2,
+			Mapper: Any(
+				func(rd io.Reader, endian binary.ByteOrder) error {
+					r = new(rect)
+					return MapSequence(Int(&r.width), Int(&r.height)).Read(rd, endian)
+				},
+				func(w io.Writer, endian binary.ByteOrder) error {
+					return MapSequence(Int(&r.width), Int(&r.height)).Write(w, endian)
+				},
+			),
+		},
+	)
+
+	assert.ErrorIs(t, m.Write(&buf, binary.BigEndian), ErrNoVariant)
+	r = &rect{width: 3, height: 4}
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, []byte{2, 0, 3, 0, 4}, buf.Bytes())
+
+	r = nil
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.Nil(t, c)
+	assert.Equal(t, &rect{width: 3, height: 4}, r)
+
+	c = &circle{radius: 5}
+	assert.ErrorIs(t, m.Write(&buf, binary.BigEndian), ErrManyVariants)
+
+	buf.Reset()
+	buf.Write([]byte{3})
+	assert.ErrorIs(t, m.Read(&buf, binary.BigEndian), ErrUnknownVariant)
+
+	buf.Reset()
+	buf.Write([]byte{1})
+	assert.ErrorIs(t, OneOf(OneOfCase{Discriminator: 1, Mapper: Int(&c.radius)}).Write(&buf, binary.BigEndian), ErrNilReadWrite, "A nil IsActive should not panic")
+	assert.ErrorIs(t, OneOf(OneOfCase{IsActive: func() bool { return true }, Discriminator: 1}).Read(&buf, binary.BigEndian), ErrNilReadWrite, "A nil Mapper should not panic")
+}
+
+func TestHeaderDispatch(t *testing.T) {
+	type header struct {
+		typ    uint8
+		length uint16
+	}
+	var (
+		buf  bytes.Buffer
+		hdr  header
+		ping uint32
+		name string
+	)
+	m := HeaderDispatch(&hdr, MapSequence(Int(&hdr.typ), Int(&hdr.length)), func(h *header) uint8 {
+		return h.typ
+	}, map[uint8]Mapper{
+		1: Int(&ping),
+		2: Any(
+			func(r io.Reader, endian binary.ByteOrder) error {
+				return FixedString(&name, int(hdr.length)).Read(r, endian)
+			},
+			func(w io.Writer, endian binary.ByteOrder) error {
+				return FixedString(&name, int(hdr.length)).Write(w, endian)
+			},
+		),
+	})
+
+	hdr = header{typ: 2, length: 5}
+	name = "hello"
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, []byte{2, 0, 5, 'h', 'e', 'l', 'l', 'o'}, buf.Bytes())
+
+	hdr, name = header{}, ""
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.Equal(t, header{typ: 2, length: 5}, hdr)
+	assert.Equal(t, "hello", name)
+
+	hdr.typ = 3
+	assert.ErrorIs(t, m.Write(&buf, binary.BigEndian), ErrUnknownVariant)
+	buf.Write([]byte{3, 0, 0})
+	assert.ErrorIs(t, m.Read(&buf, binary.BigEndian), ErrUnknownVariant)
+}
+
+func TestMaybeTrailing(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		a      uint16
+		b      uint32
+		c      uint16
+		endian = binary.BigEndian
+	)
+	m := MapSequence(
+		Int(&a),
+		MaybeTrailing(Int(&b)),
+		MaybeTrailing(Int(&c)),
+	)
+	buf.Write([]byte{0, 1, 0, 0, 0, 2})
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(1), a)
+	assert.Equal(t, uint32(2), b)
+	assert.Equal(t, uint16(0), c)
+
+	buf.Write([]byte{0, 1, 0, 0})
+	assert.ErrorIs(t, m.Read(&buf, endian), io.ErrUnexpectedEOF)
+
+	a, b, c = 1, 2, 3
+	buf.Reset()
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 1, 0, 0, 0, 2, 0, 3}, buf.Bytes())
+}
+
+type embedBase struct {
+	id uint16
+}
+
+func (b *embedBase) mapper() Mapper {
+	return Int(&b.id)
+}
+
+func TestEmbed(t *testing.T) {
+	type (
+		byValue struct {
+			embedBase
+			flag bool
+		}
+		byPointer struct {
+			*embedBase
+			flag bool
+		}
+	)
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		val    = byValue{embedBase: embedBase{id: 5}, flag: true}
+		ptr    byPointer
+	)
+	m := MapSequence(Embed(val.embedBase.mapper()), Bool(&val.flag))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 5, 1}, buf.Bytes())
+
+	m = MapSequence(EmbedPtr(&ptr.embedBase, (*embedBase).mapper), Bool(&ptr.flag))
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.NotNil(t, ptr.embedBase)
+	assert.Equal(t, uint16(5), ptr.id)
+	assert.True(t, ptr.flag)
+
+	ptr.embedBase = nil
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 1}, buf.Bytes(), "A nil embedded pointer should be written as a zero value")
+}
+
+func TestComputed(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		count  uint8
+		data   = []uint16{1, 2, 3}
+	)
+	m := MapSequence(
+		Computed(&count, func() uint8 { return uint8(len(data)) }, Int(&count)),
+		Any(
+			func(r io.Reader, endian binary.ByteOrder) error {
+				return Slice(&data, count, Int[uint16]).Read(r, endian)
+			},
+			func(w io.Writer, endian binary.ByteOrder) error {
+				return Slice(&data, count, Int[uint16]).Write(w, endian)
+			},
+		),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint8(3), count)
+	assert.Equal(t, []byte{3, 0, 1, 0, 2, 0, 3}, buf.Bytes())
+
+	count, data = 0, nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint8(3), count)
+	assert.Equal(t, []uint16{1, 2, 3}, data)
+}
+
+func TestEndian(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		a, b, c = uint16(1), uint16(2), uint16(3)
+	)
+	m := MapSequence(
+		Int(&a),
+		Endian(binary.LittleEndian, Int(&b), Int(&c)),
+	)
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, []byte{0, 1, 2, 0, 3, 0}, buf.Bytes())
+
+	a, b, c = 0, 0, 0
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.Equal(t, uint16(1), a)
+	assert.Equal(t, uint16(2), b)
+	assert.Equal(t, uint16(3), c)
+}
+
+func TestReadOnly_WriteOnly(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		val = uint16(5)
+	)
+	ro := ReadOnly(Int(&val).Read)
+	assert.ErrorIs(t, ro.Write(&buf, binary.BigEndian), ErrWriteNotSupported)
+	wo := WriteOnly(Int(&val).Write)
+	assert.NoError(t, wo.Write(&buf, binary.BigEndian))
+	assert.ErrorIs(t, wo.Read(&buf, binary.BigEndian), ErrReadNotSupported)
+
+	val = 0
+	assert.NoError(t, ro.Read(&buf, binary.BigEndian))
+	assert.Equal(t, uint16(5), val)
+}
+
+func TestInvariant(t *testing.T) {
+	var (
+		buf            bytes.Buffer
+		hasA, hasB     bool
+		hasExt, hasLen bool
+	)
+	m := Invariant(MapSequence(Bool(&hasA), Bool(&hasB)), ExactlyOne(&hasA, &hasB))
+	buf.Write([]byte{1, 0, 1, 1, 0, 0})
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.ErrorIs(t, m.Read(&buf, binary.BigEndian), ErrInvariant)
+	assert.ErrorIs(t, m.Read(&buf, binary.BigEndian), ErrInvariant)
+	assert.ErrorIs(t, m.Read(&buf, binary.BigEndian), io.EOF, "Read errors should take precedence")
+
+	m = Invariant(MapSequence(Bool(&hasExt), Bool(&hasLen)), RequiredIf(&hasExt, &hasLen))
+	buf.Write([]byte{1, 1, 0, 0, 1, 0})
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.ErrorIs(t, m.Read(&buf, binary.BigEndian), ErrInvariant)
+
+	assert.ErrorIs(t, ExactlyOne(&hasA, nil)(), ErrNilReadWrite)
+	assert.ErrorIs(t, RequiredIf(nil, &hasLen)(), ErrNilReadWrite)
+	assert.ErrorIs(t, RequiredIf(&hasExt, nil)(), ErrNilReadWrite)
+}
+
+func TestTracked(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		val    = uint16(300)
+		valid  = true
+	)
+	m := Tracked(&val, &valid, Int(&val))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{1, 1, 0x2C}, buf.Bytes())
+	val, valid = 0, false
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.True(t, valid)
+	assert.Equal(t, uint16(300), val)
+
+	valid = false
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0}, buf.Bytes(), "Invalid values should write a zero slot")
+	assert.Equal(t, uint16(300), val, "Target should be unchanged after writing")
+
+	buf.Reset()
+	buf.Write([]byte{0, 0xFF, 0xFF})
+	valid = true
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.False(t, valid)
+	assert.Equal(t, uint16(0), val, "Stale data in an invalid slot should be ignored")
+}
+
+func TestBitGated(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		flags  = uint32(1 << 3)
+		a      = uint8(1)
+		b      = uint16(2)
+	)
+	m := MapSequence(
+		Int(&flags),
+		BitGated(&flags, 0, Int(&a)),
+		BitGated(&flags, 3, Int(&b)),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0, 0x08, 0, 2}, buf.Bytes())
+
+	flags, a, b = 0, 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint32(1<<3), flags)
+	assert.Equal(t, uint8(0), a, "Field should not be read if its bit is clear")
+	assert.Equal(t, uint16(2), b)
+
+	assert.ErrorIs(t, BitGated(&flags, 32, Int(&a)).Read(&buf, endian), ErrBitRange)
+}
+
+func TestOrDefault(t *testing.T) {
+	var (
+		endian = binary.BigEndian
+		name   string
+		after  uint8
+	)
+	m := MapSequence(
+		OrDefault(&name, "unknown", PadTo(4, 0, EscapedString(&name, 4, map[byte][]byte{'\\': []byte(`\\`)})), ErrInvalidEscape),
+		Int(&after),
+	)
+	assert.NoError(t, m.Read(bytes.NewReader([]byte{'a', '\\', 'q', 'b', 7}), endian))
+	assert.Equal(t, "unknown", name)
+	assert.Equal(t, uint8(7), after, "Following fields should still be read")
+
+	assert.NoError(t, m.Read(bytes.NewReader([]byte{'a', 'b', 0, 0, 8}), endian))
+	assert.Equal(t, "ab", name)
+	assert.Equal(t, uint8(8), after)
+
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{'a', 'b'}), endian), io.ErrUnexpectedEOF, "Other errors should not be recovered")
+	assert.ErrorIs(t, OrDefault(&name, "", Int(&after)).Read(bytes.NewReader([]byte{1}), endian), ErrNoRecoverable)
+}
+
+func TestTransactional(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		a      = uint16(1)
+		b      = uint16(2)
+		fail   = errors.New("fail")
+	)
+	failing := Any(nil, func(io.Writer, binary.ByteOrder) error { return fail })
+	assert.ErrorIs(t, Transactional(MapSequence(Int(&a), failing)).Write(&buf, endian), fail)
+	assert.Equal(t, 0, buf.Len(), "Nothing should be written on failure")
+
+	m := Transactional(MapSequence(Int(&a), Int(&b)))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 1, 0, 2}, buf.Bytes())
+	a, b = 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(1), a)
+	assert.Equal(t, uint16(2), b)
+}
+
+func TestDynamicEndian(t *testing.T) {
+	var (
+		buf       bytes.Buffer
+		byteOrder binary.ByteOrder
+		marker    = make([]byte, 2)
+		val       uint32
+	)
+	m := MapSequence(
+		FixedBytes(&marker, uint8(2)),
+		Any(func(io.Reader, binary.ByteOrder) error {
+			switch string(marker) {
+			case "II":
+				byteOrder = binary.LittleEndian
+			case "MM":
+				byteOrder = binary.BigEndian
+			default:
+				return errors.New("bad marker")
+			}
+			return nil
+		}, func(io.Writer, binary.ByteOrder) error {
+			return nil
+		}),
+		DynamicEndian(&byteOrder, Int(&val)),
+	)
+
+	buf.Write([]byte{'I', 'I', 1, 0, 0, 0})
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.Equal(t, uint32(1), val)
+
+	buf.Write([]byte{'M', 'M', 0, 0, 0, 2})
+	assert.NoError(t, m.Read(&buf, binary.LittleEndian))
+	assert.Equal(t, uint32(2), val)
+
+	byteOrder, marker = binary.LittleEndian, []byte("II")
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, []byte{'I', 'I', 2, 0, 0, 0}, buf.Bytes())
+
+	buf.Reset()
+	byteOrder = nil
+	assert.NoError(t, DynamicEndian(&byteOrder, Int(&val)).Write(&buf, binary.BigEndian))
+	assert.Equal(t, []byte{0, 0, 0, 2}, buf.Bytes(), "The given endian policy should be used if none is set")
+}
+
+func TestPlaceholder(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		maxLen uint16
+		names  = []string{"a", "abcd", "ab"}
+		count  uint8
+	)
+	maxField, setMax := Placeholder(&maxLen, Int(&maxLen))
+	m := Transactional(MapSequence(
+		maxField,
+		LenSlice(&names, &count, func(s *string) Mapper {
+			return Any(
+				func(r io.Reader, endian binary.ByteOrder) error {
+					return NullTermString(s).Read(r, endian)
+				},
+				func(w io.Writer, endian binary.ByteOrder) error {
+					if uint16(len(*s)) > maxLen {
+						setMax(uint16(len(*s)))
+					}
+					return NullTermString(s).Write(w, endian)
+				},
+			)
+		}),
+	))
+	count = 3
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 4, 3, 'a', 0, 'a', 'b', 'c', 'd', 0, 'a', 'b', 0}, buf.Bytes(), "The header should reflect the value set while writing the body")
+
+	maxLen, names = 0, nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(4), maxLen)
+	assert.Equal(t, []string{"a", "abcd", "ab"}, names)
+
+	assert.ErrorIs(t, maxField.Write(&buf, endian), ErrPlaceholderRoot)
+
+	var label string
+	labelField, setLabel := Placeholder(&label, NullTermString(&label))
+	m = Transactional(MapSequence(labelField, Any(nil, func(io.Writer, binary.ByteOrder) error {
+		setLabel("longer")
+		return nil
+	})))
+	buf.Reset()
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrSizeMismatch)
+	assert.Equal(t, 0, buf.Len())
+}
+
+// testChunkedRead writes val with the Mapper returned from mapVal, and then makes sure that it's read back the same way from readers that deliver data in small chunks, like a slow network stream.
+func testChunkedRead[T any](t *testing.T, name string, val T, mapVal func(*T) Mapper) {
+	t.Helper()
+	t.Run(name, func(t *testing.T) {
+		var (
+			buf    bytes.Buffer
+			endian = binary.BigEndian
+		)
+		if !assert.NoError(t, mapVal(&val).Write(&buf, endian)) {
+			return
+		}
+		readers := map[string]func(io.Reader) io.Reader{
+			"OneByte":      iotest.OneByteReader,
+			"Half":         iotest.HalfReader,
+			"DataErr":      func(r io.Reader) io.Reader { return iotest.DataErrReader(iotest.OneByteReader(r)) },
+			"TimeoutRetry": func(r io.Reader) io.Reader { return &retryReader{reader: iotest.OneByteReader(r)} },
+		}
+		for readerName, wrap := range readers {
+			var read T
+			err := mapVal(&read).Read(wrap(bytes.NewReader(buf.Bytes())), endian)
+			if assert.NoError(t, err, readerName) {
+				assert.Equal(t, val, read, readerName)
+			}
+		}
+	})
+}
+
+// retryReader returns no bytes and no error on every other call, which is allowed but discouraged by io.Reader.
+type retryReader struct {
+	reader io.Reader
+	empty  bool
+}
+
+func (r *retryReader) Read(p []byte) (int, error) {
+	r.empty = !r.empty
+	if r.empty {
+		return 0, nil
+	}
+	return r.reader.Read(p)
+}
+
+func TestChunkedReads(t *testing.T) {
+	type (
+		lenBytes struct {
+			Buf []byte
+			Len uint16
+		}
+		lenSlice struct {
+			Vals  []uint16
+			Count uint8
+		}
+		matrix struct {
+			Vals       [][]uint8
+			Rows, Cols uint8
+		}
+		mapped struct {
+			Vals map[string]uint16
+			Len  uint32
+		}
+		tracked struct {
+			Val   uint32
+			Valid bool
+		}
+		checksum struct {
+			Name string
+			Sum  uint32
+			Sum2 uint16
+		}
+		bigNum struct {
+			Int        *big.Int
+			Rat        *big.Rat
+			Num, Denom *big.Int
+		}
+		scaled struct {
+			Lat, Lon, Scaled float64
+		}
+		morton struct {
+			X, Y uint32
+			Code uint64
+		}
+		record struct {
+			Len   uint16
+			Name  string
+			Extra uint8
+		}
+		msgpackVal struct {
+			Name  string
+			Count int
+			Tags  []string
+		}
+		msgpack struct {
+			Val msgpackVal
+			Len uint16
+		}
+		der struct {
+			Serial *big.Int
+			Data   []byte
+		}
+		optional struct {
+			Flags uint32
+			A, B  uint16
+		}
+	)
+	var escapes = map[byte][]byte{'\n': []byte(`\n`), '\\': []byte(`\\`)}
+
+	testChunkedRead(t, "Byte", byte(7), Byte)
+	testChunkedRead(t, "Bool", true, Bool)
+	testChunkedRead(t, "Bool32", true, Bool32)
+	testChunkedRead(t, "Int", uint64(0xDEADBEEFCAFE), Int[uint64])
+	testChunkedRead(t, "Float", 3.14159, Float[float64])
+	testChunkedRead(t, "Complex", complex64(1+2i), Complex[complex64])
+	testChunkedRead(t, "Varint", int64(-300000), Varint)
+	testChunkedRead(t, "Uvarint", uint64(300000), Uvarint)
+	testChunkedRead(t, "FlagBitLength", uint64(2097152), FlagBitLength)
+	testChunkedRead(t, "FixedBytes", []byte("fixed"), func(b *[]byte) Mapper { return FixedBytes(b, uint8(5)) })
+	testChunkedRead(t, "LenBytes", lenBytes{Buf: []byte("abc"), Len: 3}, func(v *lenBytes) Mapper { return LenBytes(&v.Buf, &v.Len) })
+	testChunkedRead(t, "OverheadLenBytes", lenBytes{Buf: []byte("abc")}, func(v *lenBytes) Mapper { return OverheadLenBytes(&v.Buf, &v.Len, 2) })
+	testChunkedRead(t, "FixedCodec", []byte("codec"), func(b *[]byte) Mapper {
+		noop := func([]byte) error { return nil }
+		return FixedCodec(b, 5, noop, noop)
+	})
+	testChunkedRead(t, "LenSlice", lenSlice{Vals: []uint16{1, 2, 3}, Count: 3}, func(v *lenSlice) Mapper { return LenSlice(&v.Vals, &v.Count, Int[uint16]) })
+	testChunkedRead(t, "BoundedSlice", lenSlice{Vals: []uint16{1, 2, 3}}, func(v *lenSlice) Mapper { return BoundedSlice(&v.Vals, &v.Count, 1, 5, Int[uint16]) })
+	testChunkedRead(t, "AssertSorted", lenSlice{Vals: []uint16{1, 2, 3}, Count: 3}, func(v *lenSlice) Mapper {
+		return AssertSorted(&v.Vals, &v.Count, func(a, b uint16) bool { return a < b }, Int[uint16])
+	})
+	testChunkedRead(t, "FixedStructSlice", []fixedVertex{{X: 1, Color: 2}, {Y: 3, Flags: [2]uint8{4, 5}}}, func(v *[]fixedVertex) Mapper { return FixedStructSlice(v, uint8(2)) })
+	testChunkedRead(t, "DynamicSlice", []uint32{1, 2, 3}, func(v *[]uint32) Mapper { return DynamicSlice(v, Int[uint32]) })
+	testChunkedRead(t, "Matrix", matrix{Vals: [][]uint8{{1, 2}, {3, 4}}, Rows: 2, Cols: 2}, func(v *matrix) Mapper { return Matrix(&v.Vals, &v.Rows, &v.Cols, Int[uint8]) })
+	testChunkedRead(t, "FixedMatrix", [][]float32{{1, 2}, {3, 4}}, func(v *[][]float32) Mapper { return FixedMatrix(v, 2, 2) })
+	testChunkedRead(t, "VarintDelimitedStream", []string{"a", "bc"}, func(v *[]string) Mapper { return VarintDelimitedStream(v, NullTermString) })
+	testChunkedRead(t, "ByteLenSlice", lenSlice{Vals: []uint16{1, 2, 3}}, func(v *lenSlice) Mapper { return ByteLenSlice(&v.Vals, &v.Count, 2, Int[uint16]) })
+	testChunkedRead(t, "SliceUntilBytes", lenSlice{Vals: []uint16{1, 2, 3}}, func(v *lenSlice) Mapper { return SliceUntilBytes(&v.Vals, &v.Count, Int[uint16]) })
+	testChunkedRead(t, "SparseSlice", lenSlice{Vals: []uint16{0, 0, 3, 0}}, func(v *lenSlice) Mapper { return SparseSlice(&v.Vals, &v.Count, Int[uint16]) })
+	testChunkedRead(t, "SeparatedSlice", []uint16{1, 2, 3}, func(v *[]uint16) Mapper { return SeparatedSlice(v, '\n', Int[uint16]) })
+	testChunkedRead(t, "FixedString", "fixed", func(s *string) Mapper { return FixedString(s, 8) })
+	testChunkedRead(t, "FixedStringArray", []string{"a", "bc"}, func(s *[]string) Mapper { return FixedStringArray(s, 2, 4) })
+	testChunkedRead(t, "EscapedString", "a\nb\\c", func(s *string) Mapper { return EscapedString(s, 10, escapes) })
+	testChunkedRead(t, "NullTermString", "null terminated", NullTermString)
+	testChunkedRead(t, "BoundedNullTermString", "bounded", func(s *string) Mapper { return BoundedNullTermString(s, 10) })
+	testChunkedRead(t, "VarintString", "varint", VarintString)
+	testChunkedRead(t, "MultiString", []string{"a", "bc"}, MultiString)
+	testChunkedRead(t, "Uni16NullTermString", "unicode ✓", Uni16NullTermString)
+	testChunkedRead(t, "Uni16FixedString", "unicode ✓", func(s *string) Mapper { return Uni16FixedString(s, 12) })
+	testChunkedRead(t, "NumericText", int32(-123), func(v *int32) Mapper { return NumericText(v, 8) })
+	testChunkedRead(t, "NumericTextFloat", 1.5, func(v *float64) Mapper { return NumericTextFloat(v, 8, 'f', 2) })
+	testChunkedRead(t, "ImpliedDecimalText", 12.34, func(v *float64) Mapper { return ImpliedDecimalText(v, 8, 2) })
+	testChunkedRead(t, "HexText", uint32(0xBEEF), func(v *uint32) Mapper { return HexText(v, 8) })
+	testChunkedRead(t, "DelimitedHexText", uint32(0xBEEF), func(v *uint32) Mapper { return DelimitedHexText(v, []byte("\r\n")) })
+	testChunkedRead(t, "Map", mapped{Vals: map[string]uint16{"a": 1}}, func(v *mapped) Mapper { return Map(&v.Vals, NullTermString, Int[uint16]) })
+	testChunkedRead(t, "SizedMap", mapped{Vals: map[string]uint16{"a": 1, "b": 2}}, func(v *mapped) Mapper {
+		return SizedMap(&v.Vals, &v.Len, NullTermString, Int[uint16])
+	})
+	testChunkedRead(t, "ColumnarMap", mapped{Vals: map[string]uint16{"a": 1, "b": 2}}, func(v *mapped) Mapper { return ColumnarMap(&v.Vals, NullTermString, Int[uint16]) })
+	testChunkedRead(t, "Tracked", tracked{Val: 5, Valid: true}, func(v *tracked) Mapper { return Tracked(&v.Val, &v.Valid, Int(&v.Val)) })
+	testChunkedRead(t, "BitGated", optional{Flags: 2, B: 7}, func(v *optional) Mapper {
+		return MapSequence(Int(&v.Flags), BitGated(&v.Flags, 0, Int(&v.A)), BitGated(&v.Flags, 1, Int(&v.B)))
+	})
+	testChunkedRead(t, "Checksums", checksum{Name: "checked"}, func(v *checksum) Mapper {
+		return InternetChecksum(Checksum32(NullTermString(&v.Name), &v.Sum), &v.Sum2)
+	})
+	testChunkedRead(t, "ScatteredChecksum", checksum{Name: "checked", Sum2: 1}, func(v *checksum) Mapper {
+		sum := NewScatteredChecksum(crc32.NewIEEE())
+		return MapSequence(ChecksumRegion(sum, NullTermString(&v.Name)), Int(&v.Sum2), ChecksumResult(sum, &v.Sum))
+	})
+	testChunkedRead(t, "Coded", "compressed", func(s *string) Mapper { return Coded(GzipCodec, NullTermString(s)) })
+	testChunkedRead(t, "Snappy", "compressed", func(s *string) Mapper { return Snappy(NullTermString(s)) })
+	testChunkedRead(t, "CapnpPacked", "packed", func(s *string) Mapper { return CapnpPacked(NullTermString(s)) })
+	testChunkedRead(t, "Base64", "encoded", func(s *string) Mapper { return Base64(NullTermString(s), base64.StdEncoding, NewlineTerminatedText) })
+	testChunkedRead(t, "ByteStuffed", []byte{0x7E, 0x7D, 1}, func(b *[]byte) Mapper { return ByteStuffed(FixedBytes(b, uint8(3)), 0x7E, 0x7D) })
+	testChunkedRead(t, "COBS", []byte{0, 1, 0}, func(b *[]byte) Mapper { return COBS(FixedBytes(b, uint8(3))) })
+	testChunkedRead(t, "SevenBitPacked", []byte("packed"), SevenBitPacked)
+	testChunkedRead(t, "BigNum", bigNum{Int: big.NewInt(-12345), Rat: big.NewRat(1, 3), Num: big.NewInt(2), Denom: big.NewInt(4)}, func(v *bigNum) Mapper {
+		if v.Int == nil {
+			v.Int, v.Rat, v.Num, v.Denom = new(big.Int), new(big.Rat), new(big.Int), new(big.Int)
+		}
+		return MapSequence(BigInt(v.Int), BigRat(v.Rat), BigFraction(v.Num, v.Denom))
+	})
+	testChunkedRead(t, "Scaled", scaled{Lat: 45, Lon: -90, Scaled: 1.5}, func(v *scaled) Mapper {
+		return MapSequence(Latitude(&v.Lat), Longitude(&v.Lon), ScaledCoord(&v.Scaled, 100, 2))
+	})
+	testChunkedRead(t, "MortonCode", morton{X: 3, Y: 5}, func(v *morton) Mapper { return MortonCode(&v.X, &v.Y, &v.Code) })
+	testChunkedRead(t, "Record", record{Name: "record", Extra: 1}, func(v *record) Mapper {
+		return MapSequence(Record(&v.Len, true, NullTermString(&v.Name)), Int(&v.Extra))
+	})
+	testChunkedRead(t, "PadTo", "padded", func(s *string) Mapper { return PadToPowerOfTwo(PadTo(10, ' ', NullTermString(s)), 0) })
+	testChunkedRead(t, "MsgPack", msgpack{Val: msgpackVal{Name: "msgpack", Count: -1, Tags: []string{"a"}}}, func(v *msgpack) Mapper { return MsgPack(&v.Val, &v.Len) })
+	testChunkedRead(t, "DER", der{Serial: big.NewInt(1000), Data: []byte("octets")}, func(v *der) Mapper {
+		if v.Serial == nil {
+			v.Serial = new(big.Int)
+		}
+		return DERSequence(DERInteger(v.Serial), DEROctetString(&v.Data))
+	})
+	testChunkedRead(t, "XDR", "xdr", func(s *string) Mapper { return XDRString(s, 10) })
+	testChunkedRead(t, "RoaringBitmap", []uint32{1, 2, 70000}, RoaringBitmap)
+	testChunkedRead(t, "NetipAddr", netip.MustParseAddr("2001:db8::1"), NetipAddr)
+	testChunkedRead(t, "DOSDateTime", time.Date(2020, 5, 17, 13, 45, 30, 0, time.UTC), DOSDateTime)
+	testChunkedRead(t, "NTPTime", time.Date(2020, 5, 17, 13, 45, 30, 0, time.UTC), NTPTime)
+	testChunkedRead(t, "MaybeTrailing", uint16(7), func(v *uint16) Mapper { return MaybeTrailing(Int(v)) })
+	testChunkedRead(t, "Magic", "body", func(s *string) Mapper {
+		version := uint16(1)
+		return FormatHeader([]byte("MAGC"), &version, map[uint16]Mapper{1: MapSequence(ConstantFill(2, 0xFF), NullTermString(s))})
+	})
+}
@@ -1,8 +1,18 @@
 package bin
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
+	"sort"
+)
+
+var (
+	ErrKeyOutOfRange = errors.New("map key is out of range")
+	ErrMissingField  = errors.New("named field is missing")
+	ErrFieldType     = errors.New("named field has an unexpected type")
 )
 
 type KeyMapper[K comparable] func(key *K) Mapper
@@ -56,3 +66,278 @@ func Map[K comparable, V any](target *map[K]V, keyMapper KeyMapper[K], valMapper
 		},
 	}
 }
+
+// SizedMap is similar to Map, except that the map entries are prefixed with their total size in bytes, rather than an entry count.
+// This allows a reader to skip the entire map without parsing it.
+// On write, all entries are buffered to discover the byte length, which will be set in byteLen.
+// On read, entries will be read until byteLen bytes have been consumed.
+func SizedMap[K comparable, V any, S SizeType](target *map[K]V, byteLen *S, keyMapper KeyMapper[K], valMapper ValMapper[V]) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	if byteLen == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Size(byteLen).Read(r, endian); err != nil {
+				return err
+			}
+			m := map[K]V{}
+			lr, err := limitTo(r, uint64(*byteLen))
+			if err != nil {
+				return err
+			}
+			for lr.N > 0 {
+				var (
+					key K
+					val V
+				)
+				if err := keyMapper(&key).Read(lr, endian); err != nil {
+					return err
+				}
+				if err := valMapper(&val).Read(lr, endian); err != nil {
+					return err
+				}
+				m[key] = val
+			}
+			*target = m
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			for k, v := range *target {
+				if err := keyMapper(&k).Write(&buf, endian); err != nil {
+					return err
+				}
+				if err := valMapper(&v).Write(&buf, endian); err != nil {
+					return err
+				}
+			}
+			length, err := toSize[S](buf.Len())
+			if err != nil {
+				return err
+			}
+			*byteLen = length
+			if err := Size(byteLen).Write(w, endian); err != nil {
+				return err
+			}
+			_, err = buf.WriteTo(w)
+			return err
+		},
+	}
+}
+
+// DenseEnumMap maps a map with small, dense uint8 keys in the range 0 to maxKey as a presence bitmap followed by the present values in key order.
+// The bitmap is (maxKey/8)+1 bytes, with the presence of key 0 in the least significant bit of the first byte.
+// If present is not nil, then it will be populated with the presence of each key on both read and write.
+// ErrKeyOutOfRange is returned on write if the map contains a key greater than maxKey.
+func DenseEnumMap[V any](target *map[uint8]V, maxKey uint8, valMapper ValMapper[V], present *[]bool) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	if present == nil {
+		present = new([]bool)
+	}
+	numKeys := int(maxKey) + 1
+	bitmapLen := int(maxKey)/8 + 1
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			bitmap := make([]byte, bitmapLen)
+			if _, err := io.ReadFull(r, bitmap); err != nil {
+				return err
+			}
+			m := map[uint8]V{}
+			flags := make([]bool, numKeys)
+			for i := 0; i < numKeys; i++ {
+				if bitmap[i/8]&(1<<(i%8)) == 0 {
+					continue
+				}
+				flags[i] = true
+				var val V
+				if err := valMapper(&val).Read(r, endian); err != nil {
+					return err
+				}
+				m[uint8(i)] = val
+			}
+			*target = m
+			*present = flags
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			bitmap := make([]byte, bitmapLen)
+			flags := make([]bool, numKeys)
+			for k := range *target {
+				if k > maxKey {
+					return fmt.Errorf("%w: key %d is greater than max key %d", ErrKeyOutOfRange, k, maxKey)
+				}
+				bitmap[k/8] |= 1 << (k % 8)
+				flags[k] = true
+			}
+			*present = flags
+			if _, err := w.Write(bitmap); err != nil {
+				return err
+			}
+			for i := 0; i < numKeys; i++ {
+				if !flags[i] {
+					continue
+				}
+				val := (*target)[uint8(i)]
+				if err := valMapper(&val).Write(w, endian); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// SortedMap is the same as Map, except that entries are written in the key order determined by less.
+// This produces the same output for the same map contents, which is important when the output is hashed or checksummed.
+func SortedMap[K comparable, V any](target *map[K]V, less func(a, b K) bool, keyMapper KeyMapper[K], valMapper ValMapper[V]) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: Map(target, keyMapper, valMapper).Read,
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			keys := make([]K, 0, len(*target))
+			for k := range *target {
+				keys = append(keys, k)
+			}
+			sort.Slice(keys, func(i, j int) bool {
+				return less(keys[i], keys[j])
+			})
+			var length = uint32(len(keys))
+			if err := Size(&length).Write(w, endian); err != nil {
+				return err
+			}
+			for _, k := range keys {
+				v := (*target)[k]
+				if err := keyMapper(&k).Write(w, endian); err != nil {
+					return err
+				}
+				if err := valMapper(&v).Write(w, endian); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// ColumnarMap is the same as Map, except that all keys are written contiguously, followed by all values in the same order.
+// This improves locality when scanning keys, and is the map analog of DataTable.
+// On read, the keys and values are zipped back together to populate the map.
+func ColumnarMap[K comparable, V any](target *map[K]V, keyMapper KeyMapper[K], valMapper ValMapper[V]) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				length uint32
+				keys   []K
+				vals   []V
+			)
+			if err := Size(&length).Read(r, endian); err != nil {
+				return err
+			}
+			if err := Slice(&keys, length, keyMapper).Read(r, endian); err != nil {
+				return err
+			}
+			if err := Slice(&vals, length, valMapper).Read(r, endian); err != nil {
+				return err
+			}
+			m := make(map[K]V, length)
+			for i, k := range keys {
+				m[k] = vals[i]
+			}
+			*target = m
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			// Capture the iteration order once, so keys and values are written in the same order.
+			keys := make([]K, 0, len(*target))
+			vals := make([]V, 0, len(*target))
+			for k, v := range *target {
+				keys = append(keys, k)
+				vals = append(vals, v)
+			}
+			length, err := toSize[uint32](len(keys))
+			if err != nil {
+				return err
+			}
+			return MapSequence(
+				Size(&length),
+				Slice(&keys, length, keyMapper),
+				Slice(&vals, length, valMapper),
+			).Write(w, endian)
+		},
+	}
+}
+
+// NamedField is a single field of a NamedFields record, and should be created with NamedFieldOf.
+type NamedField struct {
+	name  string
+	read  func(r io.Reader, endian binary.ByteOrder) (any, error)
+	write func(w io.Writer, endian binary.ByteOrder, val any) error
+}
+
+// NamedFieldOf creates a NamedField with the given name, that will be mapped as a T with the Mapper returned from mapVal.
+func NamedFieldOf[T any](name string, mapVal func(*T) Mapper) NamedField {
+	return NamedField{
+		name: name,
+		read: func(r io.Reader, endian binary.ByteOrder) (any, error) {
+			var val T
+			if err := mapVal(&val).Read(r, endian); err != nil {
+				return nil, err
+			}
+			return val, nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder, val any) error {
+			typed, ok := val.(T)
+			if !ok {
+				var zero T
+				return fmt.Errorf("%w: field '%s' is %T, not %T", ErrFieldType, name, val, zero)
+			}
+			return mapVal(&typed).Write(w, endian)
+		},
+	}
+}
+
+// NamedFields maps a record with a fixed schema into a map keyed by field name, which is useful for tooling and generic serializers that access fields dynamically.
+// Fields are mapped in the order given.
+// On read, a new map is populated with each field's decoded value.
+// On write, ErrMissingField is returned if a field is not in the map, and ErrFieldType is returned if its value doesn't have the field's type.
+func NamedFields(target *map[string]any, fields ...NamedField) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			m := make(map[string]any, len(fields))
+			for _, f := range fields {
+				val, err := f.read(r, endian)
+				if err != nil {
+					return err
+				}
+				m[f.name] = val
+			}
+			*target = m
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			for _, f := range fields {
+				val, ok := (*target)[f.name]
+				if !ok {
+					return fmt.Errorf("%w: '%s'", ErrMissingField, f.name)
+				}
+				if err := f.write(w, endian, val); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
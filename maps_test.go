@@ -32,3 +32,131 @@ func TestMap(t *testing.T) {
 	assert.Equal(t, data[2], false)
 	assert.Equal(t, data[3], true)
 }
+
+func TestSizedMap(t *testing.T) {
+	data := map[uint8]uint16{
+		0: 1,
+		1: 2,
+		2: 3,
+	}
+	var (
+		buf     bytes.Buffer
+		byteLen uint16
+		trailer = byte(0xFF)
+	)
+	m := MapSequence(
+		SizedMap(&data, &byteLen, Int[uint8], Int[uint16]),
+		Byte(&trailer),
+	)
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, uint16(9), byteLen)
+	assert.Equal(t, 12, buf.Len())
+	assert.Equal(t, []byte{0x0, 0x9}, buf.Bytes()[:2])
+
+	data, byteLen, trailer = nil, 0, 0
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.Equal(t, map[uint8]uint16{0: 1, 1: 2, 2: 3}, data)
+	assert.Equal(t, byte(0xFF), trailer)
+
+	var small uint8
+	data = map[uint8]uint16{}
+	for i := 0; i < 100; i++ {
+		data[uint8(i)] = uint16(i)
+	}
+	assert.ErrorIs(t, SizedMap(&data, &small, Int[uint8], Int[uint16]).Write(&buf, binary.BigEndian), ErrSizeOverflow)
+}
+
+func TestDenseEnumMap(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		present []bool
+		data    = map[uint8]uint16{
+			0: 10,
+			3: 30,
+			9: 90,
+		}
+	)
+	m := DenseEnumMap(&data, 9, Int[uint16], &present)
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, []byte{0x09, 0x02, 0, 10, 0, 30, 0, 90}, buf.Bytes())
+	assert.Equal(t, []bool{true, false, false, true, false, false, false, false, false, true}, present)
+
+	data, present = nil, nil
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.Equal(t, map[uint8]uint16{0: 10, 3: 30, 9: 90}, data)
+	assert.Equal(t, []bool{true, false, false, true, false, false, false, false, false, true}, present)
+
+	data[10] = 100
+	assert.ErrorIs(t, m.Write(&buf, binary.BigEndian), ErrKeyOutOfRange)
+}
+
+func TestSortedMap(t *testing.T) {
+	data := map[uint8]bool{
+		3: true,
+		1: true,
+		2: false,
+		0: false,
+	}
+	m := SortedMap(&data, func(a, b uint8) bool { return a < b }, Int[uint8], Bool)
+	var buf bytes.Buffer
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, []byte{0, 0, 0, 4, 0, 0, 1, 1, 2, 0, 3, 1}, buf.Bytes())
+
+	data = nil
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.Equal(t, map[uint8]bool{0: false, 1: true, 2: false, 3: true}, data)
+}
+
+func TestColumnarMap(t *testing.T) {
+	data := map[uint8]uint16{
+		1: 10,
+		2: 20,
+		3: 30,
+	}
+	m := ColumnarMap(&data, Int[uint8], Int[uint16])
+	var buf bytes.Buffer
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	out := buf.Bytes()
+	assert.Len(t, out, 4+3+6)
+	assert.Equal(t, []byte{0, 0, 0, 3}, out[:4])
+	for i, key := range out[4:7] {
+		val := binary.BigEndian.Uint16(out[7+2*i:])
+		assert.Equal(t, uint16(key)*10, val, "Values should be written in the same order as keys")
+	}
+
+	data = nil
+	assert.NoError(t, m.Read(&buf, binary.BigEndian))
+	assert.Equal(t, map[uint8]uint16{1: 10, 2: 20, 3: 30}, data)
+}
+
+func TestNamedFields(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		record = map[string]any{
+			"id":   uint16(7),
+			"name": "gopher",
+			"ok":   true,
+		}
+	)
+	m := NamedFields(&record,
+		NamedFieldOf("id", func(v *uint16) Mapper { return Int(v) }),
+		NamedFieldOf("name", func(v *string) Mapper { return NullTermString(v) }),
+		NamedFieldOf("ok", Bool),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 7, 'g', 'o', 'p', 'h', 'e', 'r', 0, 1}, buf.Bytes())
+
+	record = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, map[string]any{
+		"id":   uint16(7),
+		"name": "gopher",
+		"ok":   true,
+	}, record)
+
+	record["id"] = 7
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrFieldType)
+	delete(record, "id")
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrMissingField)
+}
@@ -0,0 +1,577 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+)
+
+var (
+	ErrInvalidMsgPack     = errors.New("invalid msgpack data")
+	ErrUnsupportedMsgPack = errors.New("unsupported type for msgpack")
+)
+
+// msgpackMaxDepth limits how deeply arrays, maps, and pointers may be nested, so untrusted input can't exhaust the stack while decoding, and cyclic values fail while encoding.
+const msgpackMaxDepth = 512
+
+// msgpackMap is the decoded form of a msgpack map, which keeps the encoded order of the entries and allows keys that aren't comparable.
+type msgpackMap []msgpackEntry
+
+type msgpackEntry struct {
+	key, val any
+}
+
+// msgpackMarshal encodes v as MessagePack.
+// Structs are encoded as maps of their exported field names, which may be overridden with a `msgpack:"name"` tag, or skipped with `msgpack:"-"`.
+// Map entries are sorted by their encoded keys so the output is deterministic.
+func msgpackMarshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := msgpackEncode(&buf, reflect.ValueOf(v), 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func msgpackHeader(buf *bytes.Buffer, n int, fix, fixMax byte, formats [3]byte) error {
+	switch {
+	case fixMax > 0 && n <= int(fixMax):
+		buf.WriteByte(fix | byte(n))
+	case formats[0] != 0 && n <= math.MaxUint8:
+		buf.Write([]byte{formats[0], byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(formats[1])
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	case uint64(n) <= math.MaxUint32:
+		buf.WriteByte(formats[2])
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		return fmt.Errorf("%w: length %d is too large", ErrUnsupportedMsgPack, n)
+	}
+	return nil
+}
+
+func msgpackEncodeUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u <= 0x7F:
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.Write([]byte{0xCC, byte(u)})
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xCD)
+		_ = binary.Write(buf, binary.BigEndian, uint16(u))
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xCE)
+		_ = binary.Write(buf, binary.BigEndian, uint32(u))
+	default:
+		buf.WriteByte(0xCF)
+		_ = binary.Write(buf, binary.BigEndian, u)
+	}
+}
+
+func msgpackEncodeInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0:
+		msgpackEncodeUint(buf, uint64(i))
+	case i >= -32:
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		buf.Write([]byte{0xD0, byte(i)})
+	case i >= math.MinInt16:
+		buf.WriteByte(0xD1)
+		_ = binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xD2)
+		_ = binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xD3)
+		_ = binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+func msgpackEncode(buf *bytes.Buffer, v reflect.Value, depth int) error {
+	if depth > msgpackMaxDepth {
+		return fmt.Errorf("%w: nested more than %d levels, which may be a cyclic value", ErrUnsupportedMsgPack, msgpackMaxDepth)
+	}
+	if !v.IsValid() {
+		buf.WriteByte(0xC0)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteByte(0xC0)
+			return nil
+		}
+		return msgpackEncode(buf, v.Elem(), depth+1)
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteByte(0xC3)
+		} else {
+			buf.WriteByte(0xC2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		msgpackEncodeInt(buf, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		msgpackEncodeUint(buf, v.Uint())
+	case reflect.Float32:
+		buf.WriteByte(0xCA)
+		_ = binary.Write(buf, binary.BigEndian, float32(v.Float()))
+	case reflect.Float64:
+		buf.WriteByte(0xCB)
+		_ = binary.Write(buf, binary.BigEndian, v.Float())
+	case reflect.String:
+		if err := msgpackHeader(buf, v.Len(), 0xA0, 31, [3]byte{0xD9, 0xDA, 0xDB}); err != nil {
+			return err
+		}
+		buf.WriteString(v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteByte(0xC0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if err := msgpackHeader(buf, v.Len(), 0, 0, [3]byte{0xC4, 0xC5, 0xC6}); err != nil {
+				return err
+			}
+			for i := 0; i < v.Len(); i++ {
+				buf.WriteByte(byte(v.Index(i).Uint()))
+			}
+			return nil
+		}
+		if err := msgpackHeader(buf, v.Len(), 0x90, 15, [3]byte{0, 0xDC, 0xDD}); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := msgpackEncode(buf, v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteByte(0xC0)
+			return nil
+		}
+		type entry struct {
+			key, val []byte
+		}
+		var entries []entry
+		iter := v.MapRange()
+		for iter.Next() {
+			var key, val bytes.Buffer
+			if err := msgpackEncode(&key, iter.Key(), depth+1); err != nil {
+				return err
+			}
+			if err := msgpackEncode(&val, iter.Value(), depth+1); err != nil {
+				return err
+			}
+			entries = append(entries, entry{key.Bytes(), val.Bytes()})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+		if err := msgpackHeader(buf, len(entries), 0x80, 15, [3]byte{0, 0xDE, 0xDF}); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			buf.Write(e.key)
+			buf.Write(e.val)
+		}
+	case reflect.Struct:
+		fields := msgpackFields(v.Type())
+		if err := msgpackHeader(buf, len(fields), 0x80, 15, [3]byte{0, 0xDE, 0xDF}); err != nil {
+			return err
+		}
+		for _, f := range fields {
+			if err := msgpackEncode(buf, reflect.ValueOf(f.name), depth+1); err != nil {
+				return err
+			}
+			if err := msgpackEncode(buf, v.Field(f.index), depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedMsgPack, v.Type())
+	}
+	return nil
+}
+
+type msgpackField struct {
+	name  string
+	index int
+}
+
+func msgpackFields(t reflect.Type) []msgpackField {
+	var fields []msgpackField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("msgpack"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, msgpackField{name: name, index: i})
+	}
+	return fields
+}
+
+// msgpackUnmarshal decodes data into the value pointed to by v.
+// ErrInvalidMsgPack is returned if data is malformed or has trailing bytes, and ErrUnsupportedMsgPack is returned if a value can't be assigned to its target.
+func msgpackUnmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: target must be a non-nil pointer", ErrUnsupportedMsgPack)
+	}
+	r := bytes.NewReader(data)
+	val, err := msgpackDecode(r, 0)
+	if err != nil {
+		return err
+	}
+	if r.Len() > 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrInvalidMsgPack, r.Len())
+	}
+	return msgpackAssign(rv.Elem(), val)
+}
+
+func msgpackRead(r *bytes.Reader, n uint64) ([]byte, error) {
+	if n > uint64(r.Len()) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidMsgPack)
+	}
+	buf := make([]byte, n)
+	_, _ = io.ReadFull(r, buf)
+	return buf, nil
+}
+
+func msgpackReadUint(r *bytes.Reader, size int) (uint64, error) {
+	buf, err := msgpackRead(r, uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, b := range buf {
+		u = u<<8 | uint64(b)
+	}
+	return u, nil
+}
+
+// msgpackDecode reads a single value, where integers are decoded as int64, or uint64 for unsigned formats, arrays are decoded as []any, and maps are decoded as msgpackMap.
+// ErrInvalidMsgPack is returned if arrays and maps are nested more than msgpackMaxDepth levels, which also bounds the recursion in msgpackAssign and msgpackGeneric.
+func msgpackDecode(r *bytes.Reader, depth int) (any, error) {
+	if depth > msgpackMaxDepth {
+		return nil, fmt.Errorf("%w: nested more than %d levels", ErrInvalidMsgPack, msgpackMaxDepth)
+	}
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidMsgPack)
+	}
+	var (
+		n    uint64
+		kind byte
+	)
+	switch {
+	case b <= 0x7F:
+		return int64(b), nil
+	case b >= 0xE0:
+		return int64(int8(b)), nil
+	case b&0xE0 == 0xA0:
+		n, kind = uint64(b&0x1F), 's'
+	case b&0xF0 == 0x90:
+		n, kind = uint64(b&0x0F), 'a'
+	case b&0xF0 == 0x80:
+		n, kind = uint64(b&0x0F), 'm'
+	case b == 0xC0:
+		return nil, nil
+	case b == 0xC2, b == 0xC3:
+		return b == 0xC3, nil
+	case b >= 0xCC && b <= 0xCF:
+		return msgpackReadUint(r, 1<<(b-0xCC))
+	case b >= 0xD0 && b <= 0xD3:
+		size := 1 << (b - 0xD0)
+		u, err := msgpackReadUint(r, size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign extend from the encoded size.
+		shift := 64 - 8*size
+		return int64(u<<shift) >> shift, nil
+	case b == 0xCA:
+		u, err := msgpackReadUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(uint32(u)), nil
+	case b == 0xCB:
+		u, err := msgpackReadUint(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(u), nil
+	case b >= 0xC4 && b <= 0xC6:
+		if n, err = msgpackReadUint(r, 1<<(b-0xC4)); err != nil {
+			return nil, err
+		}
+		kind = 'b'
+	case b >= 0xD9 && b <= 0xDB:
+		if n, err = msgpackReadUint(r, 1<<(b-0xD9)); err != nil {
+			return nil, err
+		}
+		kind = 's'
+	case b == 0xDC, b == 0xDD:
+		if n, err = msgpackReadUint(r, 2<<(b-0xDC)); err != nil {
+			return nil, err
+		}
+		kind = 'a'
+	case b == 0xDE, b == 0xDF:
+		if n, err = msgpackReadUint(r, 2<<(b-0xDE)); err != nil {
+			return nil, err
+		}
+		kind = 'm'
+	default:
+		return nil, fmt.Errorf("%w: format 0x%02X", ErrUnsupportedMsgPack, b)
+	}
+
+	switch kind {
+	case 's':
+		buf, err := msgpackRead(r, n)
+		if err != nil {
+			return nil, err
+		}
+		return string(buf), nil
+	case 'b':
+		return msgpackRead(r, n)
+	}
+	// Every element takes at least one byte, so this prevents a huge allocation from a corrupt length.
+	if n > uint64(r.Len()) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrInvalidMsgPack)
+	}
+	if kind == 'a' {
+		arr := make([]any, n)
+		for i := range arr {
+			if arr[i], err = msgpackDecode(r, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	}
+	m := make(msgpackMap, n)
+	for i := range m {
+		if m[i].key, err = msgpackDecode(r, depth+1); err != nil {
+			return nil, err
+		}
+		if m[i].val, err = msgpackDecode(r, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// msgpackGeneric converts decoded maps to map[string]any, or map[any]any if there are keys that aren't strings, for assignment to an interface.
+func msgpackGeneric(val any) (any, error) {
+	switch val := val.(type) {
+	case []any:
+		for i := range val {
+			elem, err := msgpackGeneric(val[i])
+			if err != nil {
+				return nil, err
+			}
+			val[i] = elem
+		}
+		return val, nil
+	case msgpackMap:
+		var (
+			strMap = map[string]any{}
+			anyMap = map[any]any{}
+		)
+		for _, e := range val {
+			elem, err := msgpackGeneric(e.val)
+			if err != nil {
+				return nil, err
+			}
+			if key, ok := e.key.(string); ok && strMap != nil {
+				strMap[key] = elem
+			} else {
+				strMap = nil
+			}
+			if e.key != nil && !reflect.TypeOf(e.key).Comparable() {
+				return nil, fmt.Errorf("%w: map key of type %T", ErrUnsupportedMsgPack, e.key)
+			}
+			anyMap[e.key] = elem
+		}
+		if strMap != nil {
+			return strMap, nil
+		}
+		return anyMap, nil
+	default:
+		return val, nil
+	}
+}
+
+func msgpackAssign(rv reflect.Value, val any) error {
+	if val == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	mismatch := func() error {
+		return fmt.Errorf("%w: cannot assign %T to %s", ErrUnsupportedMsgPack, val, rv.Type())
+	}
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return msgpackAssign(rv.Elem(), val)
+	case reflect.Interface:
+		generic, err := msgpackGeneric(val)
+		if err != nil {
+			return err
+		}
+		gv := reflect.ValueOf(generic)
+		if !gv.Type().AssignableTo(rv.Type()) {
+			return mismatch()
+		}
+		rv.Set(gv)
+	case reflect.Bool:
+		b, ok := val.(bool)
+		if !ok {
+			return mismatch()
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch val := val.(type) {
+		case int64:
+			i = val
+		case uint64:
+			if val > math.MaxInt64 {
+				return fmt.Errorf("%w: %d overflows %s", ErrOverflow, val, rv.Type())
+			}
+			i = int64(val)
+		default:
+			return mismatch()
+		}
+		if rv.OverflowInt(i) {
+			return fmt.Errorf("%w: %d overflows %s", ErrOverflow, i, rv.Type())
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		switch val := val.(type) {
+		case int64:
+			if val < 0 {
+				return fmt.Errorf("%w: %d overflows %s", ErrOverflow, val, rv.Type())
+			}
+			u = uint64(val)
+		case uint64:
+			u = val
+		default:
+			return mismatch()
+		}
+		if rv.OverflowUint(u) {
+			return fmt.Errorf("%w: %d overflows %s", ErrOverflow, u, rv.Type())
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		switch val := val.(type) {
+		case float32:
+			rv.SetFloat(float64(val))
+		case float64:
+			rv.SetFloat(val)
+		case int64:
+			rv.SetFloat(float64(val))
+		case uint64:
+			rv.SetFloat(float64(val))
+		default:
+			return mismatch()
+		}
+	case reflect.String:
+		switch val := val.(type) {
+		case string:
+			rv.SetString(val)
+		case []byte:
+			rv.SetString(string(val))
+		default:
+			return mismatch()
+		}
+	case reflect.Slice, reflect.Array:
+		var elems []any
+		switch val := val.(type) {
+		case []any:
+			elems = val
+		case []byte:
+			if rv.Type().Elem().Kind() != reflect.Uint8 {
+				return mismatch()
+			}
+			for _, b := range val {
+				elems = append(elems, uint64(b))
+			}
+		default:
+			return mismatch()
+		}
+		if rv.Kind() == reflect.Array {
+			if len(elems) != rv.Len() {
+				return fmt.Errorf("%w: expected %d elements for %s, but found %d", ErrUnsupportedMsgPack, rv.Len(), rv.Type(), len(elems))
+			}
+		} else {
+			rv.Set(reflect.MakeSlice(rv.Type(), len(elems), len(elems)))
+		}
+		for i, elem := range elems {
+			if err := msgpackAssign(rv.Index(i), elem); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		m, ok := val.(msgpackMap)
+		if !ok {
+			return mismatch()
+		}
+		rv.Set(reflect.MakeMapWithSize(rv.Type(), len(m)))
+		for _, e := range m {
+			key := reflect.New(rv.Type().Key()).Elem()
+			if err := msgpackAssign(key, e.key); err != nil {
+				return err
+			}
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			if err := msgpackAssign(elem, e.val); err != nil {
+				return err
+			}
+			rv.SetMapIndex(key, elem)
+		}
+	case reflect.Struct:
+		m, ok := val.(msgpackMap)
+		if !ok {
+			return mismatch()
+		}
+		fields := map[string]int{}
+		for _, f := range msgpackFields(rv.Type()) {
+			fields[f.name] = f.index
+		}
+		for _, e := range m {
+			name, ok := e.key.(string)
+			if !ok {
+				return fmt.Errorf("%w: struct field name of type %T", ErrUnsupportedMsgPack, e.key)
+			}
+			// Unknown fields are ignored, so fields can be added without breaking older readers.
+			idx, ok := fields[name]
+			if !ok {
+				continue
+			}
+			if err := msgpackAssign(rv.Field(idx), e.val); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedMsgPack, rv.Type())
+	}
+	return nil
+}
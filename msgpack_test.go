@@ -0,0 +1,103 @@
+package bin
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestMsgpackMarshal(t *testing.T) {
+	tests := map[string]struct {
+		val      any
+		expected []byte
+	}{
+		"Nil":              {nil, []byte{0xC0}},
+		"True":             {true, []byte{0xC3}},
+		"Positive fixint":  {int8(127), []byte{0x7F}},
+		"Negative fixint":  {int64(-32), []byte{0xE0}},
+		"Uint8":            {uint16(200), []byte{0xCC, 0xC8}},
+		"Int8":             {int32(-33), []byte{0xD0, 0xDF}},
+		"Uint16":           {256, []byte{0xCD, 0x01, 0x00}},
+		"Int16":            {-129, []byte{0xD1, 0xFF, 0x7F}},
+		"Uint32":           {uint64(1 << 16), []byte{0xCE, 0x00, 0x01, 0x00, 0x00}},
+		"Int64":            {int64(math.MinInt64), []byte{0xD3, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		"Uint64":           {uint64(math.MaxUint64), []byte{0xCF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		"Float32":          {float32(1.5), []byte{0xCA, 0x3F, 0xC0, 0x00, 0x00}},
+		"Float64":          {1.5, []byte{0xCB, 0x3F, 0xF8, 0, 0, 0, 0, 0, 0}},
+		"Fixstr":           {"abc", []byte{0xA3, 'a', 'b', 'c'}},
+		"Str8":             {string(bytes.Repeat([]byte{'a'}, 32)), append([]byte{0xD9, 32}, bytes.Repeat([]byte{'a'}, 32)...)},
+		"Bin8":             {[]byte{1, 2}, []byte{0xC4, 0x02, 0x01, 0x02}},
+		"Fixarray":         {[]int{1, -1}, []byte{0x92, 0x01, 0xFF}},
+		"Sorted map":       {map[string]int{"b": 2, "a": 1}, []byte{0x82, 0xA1, 'a', 0x01, 0xA1, 'b', 0x02}},
+		"Nil slice":        {[]string(nil), []byte{0xC0}},
+		"Array16":          {make([]bool, 16), append([]byte{0xDC, 0x00, 0x10}, bytes.Repeat([]byte{0xC2}, 16)...)},
+		"Pointer to value": {&[]uint8{}, []byte{0xC4, 0x00}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := msgpackMarshal(tc.val)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, data)
+		})
+	}
+
+	_, err := msgpackMarshal(make(chan int))
+	assert.ErrorIs(t, err, ErrUnsupportedMsgPack)
+}
+
+func TestMsgpackUnmarshal(t *testing.T) {
+	type inner struct {
+		Values []float64
+	}
+	type record struct {
+		Compact bool   `msgpack:"compact"`
+		Schema  int    `msgpack:"schema"`
+		Skipped string `msgpack:"-"`
+		Inner   *inner
+		Labels  map[uint8]string
+		Extra   any
+	}
+	val := record{
+		Compact: true,
+		Inner:   &inner{Values: []float64{1.5, -2}},
+		Labels:  map[uint8]string{1: "one", 2: "two"},
+		Extra:   map[string]any{"list": []any{int64(1), "two", nil}},
+	}
+	data, err := msgpackMarshal(val)
+	assert.NoError(t, err)
+	// The example from msgpack.org is the start of the encoded struct.
+	assert.Equal(t, []byte("\x85\xa7compact\xc3\xa6schema\x00"), data[:18])
+
+	var decoded record
+	assert.NoError(t, msgpackUnmarshal(data, &decoded))
+	assert.Equal(t, val, decoded)
+
+	var small int8
+	assert.ErrorIs(t, msgpackUnmarshal([]byte{0xCC, 0xC8}, &small), ErrOverflow)
+	assert.ErrorIs(t, msgpackUnmarshal([]byte{0xA3, 'a'}, &decoded), ErrInvalidMsgPack)
+	assert.ErrorIs(t, msgpackUnmarshal([]byte{0x01, 0x02}, &small), ErrInvalidMsgPack)
+	assert.ErrorIs(t, msgpackUnmarshal([]byte{0xDD, 0xFF, 0xFF, 0xFF, 0xFF}, &decoded), ErrInvalidMsgPack)
+	assert.ErrorIs(t, msgpackUnmarshal([]byte{0xA1, 'a'}, &small), ErrUnsupportedMsgPack)
+	assert.ErrorIs(t, msgpackUnmarshal([]byte{0xD4, 0x01, 0x00}, &decoded), ErrUnsupportedMsgPack)
+}
+
+func TestMsgpack_Depth(t *testing.T) {
+	nested := func(depth int) []byte {
+		return append(bytes.Repeat([]byte{0x91}, depth), 0x00)
+	}
+	var val any
+	assert.NoError(t, msgpackUnmarshal(nested(msgpackMaxDepth), &val))
+	assert.ErrorIs(t, msgpackUnmarshal(nested(msgpackMaxDepth+1), &val), ErrInvalidMsgPack)
+	assert.ErrorIs(t, msgpackUnmarshal(nested(20<<20), &val), ErrInvalidMsgPack, "Deeply nested input should return an error rather than exhausting the stack")
+
+	cyclicMap := map[string]any{}
+	cyclicMap["self"] = cyclicMap
+	_, err := msgpackMarshal(cyclicMap)
+	assert.ErrorIs(t, err, ErrUnsupportedMsgPack)
+
+	cyclicSlice := []any{nil}
+	cyclicSlice[0] = cyclicSlice
+	_, err = msgpackMarshal(cyclicSlice)
+	assert.ErrorIs(t, err, ErrUnsupportedMsgPack)
+}
@@ -0,0 +1,99 @@
+package bin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+)
+
+var (
+	ErrInvalidAddr = errors.New("invalid address")
+)
+
+const (
+	addrFamilyNone byte = 0
+	addrFamilyIPv4 byte = 4
+	addrFamilyIPv6 byte = 6
+)
+
+// NetipAddr maps a netip.Addr as an address family byte followed by the address bytes.
+// The family is 4 for an IPv4 address followed by 4 bytes, 6 for an IPv6 address followed by 16 bytes, or 0 for the zero Addr with no following bytes.
+// IPv6 zones are not supported, and ErrInvalidAddr is returned on write if the address has a zone.
+func NetipAddr(addr *netip.Addr) Mapper {
+	if addr == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			var family [1]byte
+			if _, err := io.ReadFull(r, family[:]); err != nil {
+				return err
+			}
+			switch family[0] {
+			case addrFamilyNone:
+				*addr = netip.Addr{}
+			case addrFamilyIPv4:
+				var ip [4]byte
+				if _, err := io.ReadFull(r, ip[:]); err != nil {
+					return err
+				}
+				*addr = netip.AddrFrom4(ip)
+			case addrFamilyIPv6:
+				var ip [16]byte
+				if _, err := io.ReadFull(r, ip[:]); err != nil {
+					return err
+				}
+				*addr = netip.AddrFrom16(ip)
+			default:
+				return fmt.Errorf("%w: unknown address family %d", ErrInvalidAddr, family[0])
+			}
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			var out []byte
+			switch {
+			case !addr.IsValid():
+				out = []byte{addrFamilyNone}
+			case addr.Zone() != "":
+				return fmt.Errorf("%w: address zone '%s' cannot be mapped", ErrInvalidAddr, addr.Zone())
+			case addr.Is4():
+				ip := addr.As4()
+				out = append([]byte{addrFamilyIPv4}, ip[:]...)
+			default:
+				ip := addr.As16()
+				out = append([]byte{addrFamilyIPv6}, ip[:]...)
+			}
+			_, err := w.Write(out)
+			return err
+		},
+	)
+}
+
+// NetipAddrPort maps a netip.AddrPort as a NetipAddr followed by the port as a uint16.
+func NetipAddrPort(ap *netip.AddrPort) Mapper {
+	if ap == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				addr netip.Addr
+				port uint16
+			)
+			if err := MapSequence(NetipAddr(&addr), Int(&port)).Read(r, endian); err != nil {
+				return err
+			}
+			*ap = netip.AddrPortFrom(addr, port)
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var (
+				addr = ap.Addr()
+				port = ap.Port()
+			)
+			return MapSequence(NetipAddr(&addr), Int(&port)).Write(w, endian)
+		},
+	)
+}
@@ -0,0 +1,53 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"net/netip"
+	"testing"
+)
+
+func TestNetipAddr(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		v4     = netip.MustParseAddr("192.168.1.10")
+		v6     = netip.MustParseAddr("2001:db8::1")
+		mapped = netip.MustParseAddr("::ffff:10.0.0.1")
+		zero   netip.Addr
+	)
+	m := MapSequence(NetipAddr(&v4), NetipAddr(&v6), NetipAddr(&mapped), NetipAddr(&zero))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{4, 192, 168, 1, 10}, buf.Bytes()[:5])
+	assert.Equal(t, 5+17+17+1, buf.Len())
+
+	v4, v6, mapped, zero = netip.Addr{}, netip.Addr{}, netip.Addr{}, netip.MustParseAddr("127.0.0.1")
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, netip.MustParseAddr("192.168.1.10"), v4)
+	assert.Equal(t, netip.MustParseAddr("2001:db8::1"), v6)
+	assert.Equal(t, netip.MustParseAddr("::ffff:10.0.0.1"), mapped, "IPv4-mapped IPv6 addresses should retain their family")
+	assert.False(t, zero.IsValid())
+
+	zoned := netip.MustParseAddr("fe80::1%eth0")
+	assert.ErrorIs(t, NetipAddr(&zoned).Write(&buf, endian), ErrInvalidAddr)
+	buf.Reset()
+	buf.WriteByte(5)
+	assert.ErrorIs(t, NetipAddr(&v4).Read(&buf, endian), ErrInvalidAddr)
+}
+
+func TestNetipAddrPort(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		ap     = netip.MustParseAddrPort("[2001:db8::1]:8080")
+	)
+	m := NetipAddrPort(&ap)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, 19, buf.Len())
+	assert.Equal(t, []byte{0x1F, 0x90}, buf.Bytes()[17:])
+
+	ap = netip.AddrPort{}
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, netip.MustParseAddrPort("[2001:db8::1]:8080"), ap)
+}
@@ -0,0 +1,99 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+var (
+	ErrFieldOverlap = errors.New("positional fields overlap")
+)
+
+// PositionalField is a field at an absolute byte offset within a record mapped with PositionalFields.
+type PositionalField struct {
+	Offset int
+	Mapper Mapper
+}
+
+// PositionalFields maps a fixed-length record of recordLen bytes where each field is located at an absolute byte offset, as is common in legacy fixed-format files.
+// Fields may be given in any order, and are mapped in offset order.
+// On read, any bytes before a field's offset are skipped, as are any bytes after the last field.
+// On write, gaps between fields and any bytes after the last field are filled with zeros.
+// The record is buffered before it's written, so nothing is written if a field fails or is out of place.
+// ErrFieldOverlap is returned if a field extends past the offset of the next field, and ErrSizeMismatch is returned if the last field extends past the end of the record.
+func PositionalFields(recordLen int, fields ...PositionalField) Mapper {
+	return PaddedPositionalFields(recordLen, 0, fields...)
+}
+
+// PaddedPositionalFields is the same as PositionalFields, except that gaps are filled with the given pad byte on write.
+// This is helpful for text based formats that pad with spaces.
+func PaddedPositionalFields(recordLen int, pad byte, fields ...PositionalField) Mapper {
+	sorted := make([]PositionalField, len(fields))
+	copy(sorted, fields)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Offset < sorted[j].Offset
+	})
+	for i, f := range sorted {
+		if f.Mapper == nil {
+			return nilMapping
+		}
+		if f.Offset < 0 || f.Offset > recordLen {
+			return errMapping(fmt.Errorf("%w: field offset %d is outside of the %d byte record", ErrOutOfBounds, f.Offset, recordLen))
+		}
+		if i > 0 && sorted[i-1].Offset == f.Offset {
+			return errMapping(fmt.Errorf("%w: multiple fields at offset %d", ErrFieldOverlap, f.Offset))
+		}
+	}
+	// checkPos validates the position after the field at index i has been mapped.
+	checkPos := func(i int, pos int64) error {
+		if i+1 < len(sorted) && pos > int64(sorted[i+1].Offset) {
+			return fmt.Errorf("%w: field at offset %d ends at %d, which is past the next field at offset %d", ErrFieldOverlap, sorted[i].Offset, pos, sorted[i+1].Offset)
+		}
+		if pos > int64(recordLen) {
+			return fmt.Errorf("%w: field at offset %d ends at %d, which exceeds the record size of %d", ErrSizeMismatch, sorted[i].Offset, pos, recordLen)
+		}
+		return nil
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			cr := &countingReader{reader: r}
+			for i, f := range sorted {
+				if _, err := io.CopyN(io.Discard, cr, int64(f.Offset)-cr.n); err != nil {
+					return err
+				}
+				if err := f.Mapper.Read(cr, endian); err != nil {
+					return err
+				}
+				if err := checkPos(i, cr.n); err != nil {
+					return err
+				}
+			}
+			_, err := io.CopyN(io.Discard, cr, int64(recordLen)-cr.n)
+			return err
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			cw := &countingWriter{writer: &buf}
+			for i, f := range sorted {
+				if _, err := cw.Write(bytes.Repeat([]byte{pad}, int(int64(f.Offset)-cw.n))); err != nil {
+					return err
+				}
+				if err := f.Mapper.Write(cw, endian); err != nil {
+					return err
+				}
+				if err := checkPos(i, cw.n); err != nil {
+					return err
+				}
+			}
+			if _, err := cw.Write(bytes.Repeat([]byte{pad}, int(int64(recordLen)-cw.n))); err != nil {
+				return err
+			}
+			_, err := buf.WriteTo(w)
+			return err
+		},
+	)
+}
@@ -0,0 +1,64 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPositionalFields(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		name   = "ACME"
+		qty    = uint16(42)
+		flag   = true
+	)
+	m := PaddedPositionalFields(12, ' ',
+		PositionalField{Offset: 8, Mapper: Int(&qty)},
+		PositionalField{Offset: 0, Mapper: FixedString(&name, 6)},
+		PositionalField{Offset: 10, Mapper: Bool(&flag)},
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{'A', 'C', 'M', 'E', 0, 0, ' ', ' ', 0, 42, 1, ' '}, buf.Bytes())
+
+	name, qty, flag = "", 0, false
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, "ACME", name)
+	assert.Equal(t, uint16(42), qty)
+	assert.True(t, flag)
+	assert.Equal(t, 0, buf.Len(), "The whole record should be consumed")
+
+	buf.Reset()
+	assert.NoError(t, PositionalFields(4, PositionalField{Offset: 1, Mapper: Int(&qty)}).Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 42, 0}, buf.Bytes())
+}
+
+func TestPositionalFields_Neg(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		a, b   uint32
+	)
+	m := PositionalFields(8,
+		PositionalField{Offset: 0, Mapper: Int(&a)},
+		PositionalField{Offset: 2, Mapper: Int(&b)},
+	)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrFieldOverlap)
+	assert.Zero(t, buf.Len(), "Nothing should be written if a field overlaps")
+	assert.ErrorIs(t, m.Read(bytes.NewReader(make([]byte, 8)), endian), ErrFieldOverlap)
+
+	m = PositionalFields(6, PositionalField{Offset: 4, Mapper: Int(&a)})
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrSizeMismatch)
+	assert.Zero(t, buf.Len(), "Nothing should be written if a field extends past the record")
+
+	m = PositionalFields(8,
+		PositionalField{Offset: 4, Mapper: Int(&a)},
+		PositionalField{Offset: 4, Mapper: Int(&b)},
+	)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrFieldOverlap)
+
+	m = PositionalFields(8, PositionalField{Offset: 9, Mapper: Int(&a)})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrOutOfBounds)
+}
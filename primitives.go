@@ -2,9 +2,17 @@ package bin
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 )
 
+var (
+	ErrTooManyFlags = errors.New("more flags specified than bits available")
+	ErrOverflow     = errors.New("integer overflow")
+	ErrInvalidWidth = errors.New("invalid width")
+)
+
 // Byte will map a single byte.
 func Byte(b *byte) Mapper {
 	if b == nil {
@@ -35,6 +43,55 @@ func Bool(b *bool) Mapper {
 	}
 }
 
+// Bool32 will map a 4-byte boolean, such as a Win32 BOOL.
+// This is the same as BoolN with a width of 4.
+func Bool32(b *bool) Mapper {
+	return BoolN(b, 4)
+}
+
+// BoolN will map a boolean that is width bytes wide.
+// On read, any non-zero value is considered true.
+// On write, true is written as the integer 1 according to the endian policy, and false is written as all zero bytes.
+// ErrInvalidWidth is returned if width is not positive.
+func BoolN(b *bool, width int) Mapper {
+	if b == nil {
+		return nilMapping
+	}
+	if width <= 0 {
+		return errMapping(fmt.Errorf("%w: boolean width %d", ErrInvalidWidth, width))
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			buf := make([]byte, width)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return err
+			}
+			*b = false
+			for _, v := range buf {
+				if v != 0 {
+					*b = true
+					break
+				}
+			}
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			buf := make([]byte, width)
+			if *b {
+				probe := make([]byte, 2)
+				endian.PutUint16(probe, 1)
+				if probe[0] == 1 {
+					buf[0] = 1
+				} else {
+					buf[width-1] = 1
+				}
+			}
+			_, err := w.Write(buf)
+			return err
+		},
+	}
+}
+
 type AnyInt interface {
 	int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64
 }
@@ -54,6 +111,127 @@ func Int[T AnyInt](i *T) Mapper {
 	}
 }
 
+type flagType interface {
+	uint8 | uint16 | uint32 | uint64
+}
+
+func flagsMapper[T flagType](value *T, bits int, flags []*bool) Mapper {
+	if value == nil {
+		return nilMapping
+	}
+	if len(flags) > bits {
+		return errMapping(fmt.Errorf("%w: %d flags specified for a %d-bit value", ErrTooManyFlags, len(flags), bits))
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if err := binary.Read(r, endian, value); err != nil {
+				return err
+			}
+			for i, flag := range flags {
+				if flag == nil {
+					continue
+				}
+				*flag = *value&(T(1)<<i) != 0
+			}
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			val := *value
+			for i, flag := range flags {
+				if flag == nil {
+					continue
+				}
+				if *flag {
+					val |= T(1) << i
+				} else {
+					val &^= T(1) << i
+				}
+			}
+			*value = val
+			return binary.Write(w, endian, value)
+		},
+	}
+}
+
+// Flags8 maps each bit of a uint8 to a boolean flag, starting with the least significant bit.
+// The raw value will be stored in value on read, and recomputed from the flags on write.
+// Bits without a corresponding flag, or with a nil flag, are preserved from value on write.
+// An error will be returned from Read and Write if more than 8 flags are given.
+func Flags8(value *uint8, flags ...*bool) Mapper {
+	return flagsMapper(value, 8, flags)
+}
+
+// Flags16 is the same as Flags8, except that it works with a uint16 and up to 16 flags.
+func Flags16(value *uint16, flags ...*bool) Mapper {
+	return flagsMapper(value, 16, flags)
+}
+
+// Flags32 is the same as Flags8, except that it works with a uint32 and up to 32 flags.
+func Flags32(value *uint32, flags ...*bool) Mapper {
+	return flagsMapper(value, 32, flags)
+}
+
+// Flags64 is the same as Flags8, except that it works with a uint64 and up to 64 flags.
+func Flags64(value *uint64, flags ...*bool) Mapper {
+	return flagsMapper(value, 64, flags)
+}
+
+// checkedAdd adds a and b, returning ErrOverflow if the result overflows T.
+func checkedAdd[T AnyInt](a, b T) (T, error) {
+	sum := a + b
+	var zero T
+	if (b > zero && sum < a) || (b < zero && sum > a) {
+		return 0, fmt.Errorf("%w: %d + %d", ErrOverflow, a, b)
+	}
+	return sum, nil
+}
+
+// checkedSub subtracts b from a, returning ErrOverflow if the result overflows T.
+func checkedSub[T AnyInt](a, b T) (T, error) {
+	diff := a - b
+	var zero T
+	if (b > zero && diff > a) || (b < zero && diff < a) {
+		return 0, fmt.Errorf("%w: %d - %d", ErrOverflow, a, b)
+	}
+	return diff, nil
+}
+
+// RelativeTo maps target as a delta from the value in base, using m to map target.
+// The base would typically be populated by an earlier Mapper, such as a file-level epoch.
+// On write, target is temporarily set to the delta from base while m writes it.
+// On read, base is added to the value read by m.
+// ErrOverflow is returned if the delta or sum overflows T.
+func RelativeTo[T AnyInt](target *T, base *T, m Mapper) Mapper {
+	if target == nil || base == nil || m == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if err := m.Read(r, endian); err != nil {
+				return err
+			}
+			val, err := checkedAdd(*target, *base)
+			if err != nil {
+				return err
+			}
+			*target = val
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			orig := *target
+			delta, err := checkedSub(orig, *base)
+			if err != nil {
+				return err
+			}
+			*target = delta
+			defer func() {
+				*target = orig
+			}()
+			return m.Write(w, endian)
+		},
+	}
+}
+
 type AnyFloat interface {
 	float32 | float64
 }
@@ -77,6 +255,13 @@ type AnyComplex interface {
 	complex64 | complex128
 }
 
+// AnyOrdered is any type that supports the ordering operators, including types derived from them.
+type AnyOrdered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
 // Complex will map a complex64/128 number.
 func Complex[T AnyComplex](target *T) Mapper {
 	return Any(
@@ -89,6 +274,29 @@ func Complex[T AnyComplex](target *T) Mapper {
 	)
 }
 
+// ComplexParts maps the real and imaginary parts of a complex number separately, through realPart and imagPart.
+// The given Mapper should map realPart and imagPart in whatever way the format requires, which allows other fields to be mapped between them, or the parts to be stored as something other than a float64.
+// On write, realPart and imagPart are set from target before writing with m.
+// On read, target is set from realPart and imagPart after reading with m.
+func ComplexParts(target *complex128, realPart, imagPart *float64, m Mapper) Mapper {
+	if target == nil || realPart == nil || imagPart == nil || m == nil {
+		return nilMapping
+	}
+	return NewEventHandler(m, EventHandler{
+		AfterRead: func(err error) error {
+			if err != nil {
+				return err
+			}
+			*target = complex(*realPart, *imagPart)
+			return nil
+		},
+		BeforeWrite: func() error {
+			*realPart, *imagPart = real(*target), imag(*target)
+			return nil
+		},
+	})
+}
+
 var _ io.ByteReader = (*unbufferedByteReader)(nil)
 
 type unbufferedByteReader struct {
@@ -100,7 +308,8 @@ func (u *unbufferedByteReader) ReadByte() (byte, error) {
 	if len(u.buf) == 0 {
 		u.buf = make([]byte, 1)
 	}
-	_, err := u.reader.Read(u.buf)
+	// io.ReadFull handles readers that return a byte along with io.EOF, or no bytes and no error.
+	_, err := io.ReadFull(u.reader, u.buf)
 	if err != nil {
 		return 0, err
 	}
@@ -154,3 +363,61 @@ func Uvarint(target *uint64) Mapper {
 		},
 	)
 }
+
+// flagBitLengthMax is the largest length that can be encoded in the 4 bytes allowed by MQTT.
+const flagBitLengthMax = 1<<28 - 1
+
+// FlagBitLength maps a length with the variable length encoding used for MQTT's remaining length field.
+// Each byte holds 7 bits of the length, least significant group first, and the high bit is set if another byte follows.
+// At most 4 bytes are allowed, so the largest length is 268,435,455.
+// ErrOverflow is returned on write if the length is too large, and on read if the continuation bit is set in the 4th byte.
+func FlagBitLength(target *uint64) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			var (
+				val uint64
+				ubr = &unbufferedByteReader{reader: r}
+			)
+			for i := 0; i < 4; i++ {
+				b, err := ubr.ReadByte()
+				if err != nil {
+					if i > 0 && errors.Is(err, io.EOF) {
+						return io.ErrUnexpectedEOF
+					}
+					return err
+				}
+				val |= uint64(b&0x7F) << (7 * i)
+				if b&0x80 == 0 {
+					*target = val
+					return nil
+				}
+			}
+			return fmt.Errorf("%w: length exceeds 4 bytes", ErrOverflow)
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			if *target > flagBitLengthMax {
+				return fmt.Errorf("%w: length %d exceeds the maximum of %d", ErrOverflow, *target, flagBitLengthMax)
+			}
+			var (
+				buf []byte
+				val = *target
+			)
+			for {
+				b := byte(val & 0x7F)
+				val >>= 7
+				if val > 0 {
+					b |= 0x80
+				}
+				buf = append(buf, b)
+				if val == 0 {
+					break
+				}
+			}
+			_, err := w.Write(buf)
+			return err
+		},
+	)
+}
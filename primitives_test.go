@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"testing"
 )
 
@@ -118,3 +119,156 @@ func TestUvarint(t *testing.T) {
 	assert.Equal(t, uint64(257), v1)
 	assert.Equal(t, uint64(258), v2)
 }
+
+func TestFlags16(t *testing.T) {
+	var (
+		buf                 bytes.Buffer
+		endian              = binary.BigEndian
+		value               uint16
+		first, second, last bool
+	)
+	buf.Write([]byte{0x80, 0x01})
+	flags := make([]*bool, 16)
+	flags[0], flags[1], flags[15] = &first, &second, &last
+	m := Flags16(&value, flags...)
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(0x8001), value)
+	assert.True(t, first)
+	assert.False(t, second)
+	assert.True(t, last)
+
+	first, second = false, true
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint16(0x8002), value)
+	assert.Equal(t, []byte{0x80, 0x02}, buf.Bytes())
+
+	var b8 uint8
+	flags = append(flags[:8], &first)
+	assert.ErrorIs(t, Flags8(&b8, flags...).Read(&buf, endian), ErrTooManyFlags)
+	assert.ErrorIs(t, Flags8(&b8, flags...).Write(&buf, endian), ErrTooManyFlags)
+}
+
+func TestRelativeTo(t *testing.T) {
+	var (
+		buf       bytes.Buffer
+		endian    = binary.BigEndian
+		epoch     = int64(1_700_000_000)
+		timestamp = int64(1_700_000_042)
+	)
+	m := MapSequence(
+		Int(&epoch),
+		RelativeTo(&timestamp, &epoch, Int(&timestamp)),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, int64(1_700_000_042), timestamp, "Target should be restored after writing")
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 42}, buf.Bytes()[8:])
+
+	epoch, timestamp = 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, int64(1_700_000_042), timestamp)
+
+	var (
+		base = uint8(10)
+		val  = uint8(5)
+	)
+	assert.ErrorIs(t, RelativeTo(&val, &base, Int(&val)).Write(&buf, endian), ErrOverflow)
+	buf.Reset()
+	buf.WriteByte(250)
+	assert.ErrorIs(t, RelativeTo(&val, &base, Int(&val)).Read(&buf, endian), ErrOverflow)
+}
+
+func TestBoolN(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		a, b = true, false
+	)
+	m := MapSequence(Bool32(&a), BoolN(&b, 2))
+	assert.NoError(t, m.Write(&buf, binary.BigEndian))
+	assert.Equal(t, []byte{0, 0, 0, 1, 0, 0}, buf.Bytes())
+	buf.Reset()
+	assert.NoError(t, m.Write(&buf, binary.LittleEndian))
+	assert.Equal(t, []byte{1, 0, 0, 0, 0, 0}, buf.Bytes())
+
+	buf.Reset()
+	buf.Write([]byte{0, 0x80, 0, 0, 0, 0})
+	a, b = false, true
+	assert.NoError(t, m.Read(&buf, binary.LittleEndian))
+	assert.True(t, a, "Any non-zero value should be read as true")
+	assert.False(t, b)
+
+	assert.ErrorIs(t, BoolN(&b, 0).Read(&buf, binary.LittleEndian), ErrInvalidWidth)
+}
+
+func TestComplexParts(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		c      = complex(1.5, -2.5)
+		re, im float64
+		unit   uint8 = 7
+		im32   float32
+	)
+	m := ComplexParts(&c, &re, &im, MapSequence(
+		Float(&re),
+		Int(&unit),
+		Any(
+			func(r io.Reader, endian binary.ByteOrder) error {
+				if err := Float(&im32).Read(r, endian); err != nil {
+					return err
+				}
+				im = float64(im32)
+				return nil
+			},
+			func(w io.Writer, endian binary.ByteOrder) error {
+				im32 = float32(im)
+				return Float(&im32).Write(w, endian)
+			},
+		),
+	))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, 8+1+4, buf.Len())
+
+	c, re, im, unit = 0, 0, 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, complex(1.5, -2.5), c)
+	assert.Equal(t, uint8(7), unit)
+}
+
+func TestFlagBitLength(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		val    uint64
+	)
+	m := FlagBitLength(&val)
+	// Examples from the MQTT 3.1.1 specification, section 2.2.3
+	for expected, encoded := range map[uint64][]byte{
+		0:         {0x00},
+		127:       {0x7F},
+		128:       {0x80, 0x01},
+		16383:     {0xFF, 0x7F},
+		16384:     {0x80, 0x80, 0x01},
+		2097151:   {0xFF, 0xFF, 0x7F},
+		2097152:   {0x80, 0x80, 0x80, 0x01},
+		268435455: {0xFF, 0xFF, 0xFF, 0x7F},
+	} {
+		buf.Reset()
+		val = expected
+		assert.NoError(t, m.Write(&buf, endian))
+		assert.Equal(t, encoded, buf.Bytes())
+		val = 0
+		assert.NoError(t, m.Read(&buf, endian))
+		assert.Equal(t, expected, val)
+	}
+
+	val = 268435456
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrOverflow)
+
+	buf.Reset()
+	buf.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x01})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrOverflow)
+
+	buf.Reset()
+	buf.Write([]byte{0x80})
+	assert.ErrorIs(t, m.Read(&buf, endian), io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,142 @@
+package bin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrRecursionLimit   = errors.New("recursion limit exceeded")
+	ErrInvalidRecursion = errors.New("recursion limit must not be negative")
+)
+
+// recursionBudget is the nesting that's still allowed for a RecursionBudget operation, which is shared by every Nested mapper within it.
+type recursionBudget struct {
+	limit     int
+	remaining int
+}
+
+// enter runs fn one level deeper, and returns ErrRecursionLimit instead if the budget is exhausted.
+func (b *recursionBudget) enter(fn func() error) error {
+	if b.remaining == 0 {
+		return fmt.Errorf("%w: nesting is limited to %d levels", ErrRecursionLimit, b.limit)
+	}
+	b.remaining--
+	defer func() {
+		b.remaining++
+	}()
+	return fn()
+}
+
+var _ io.Reader = (*budgetReader)(nil)
+
+// budgetReader carries a recursionBudget through a read, so Nested mappers can find it.
+type budgetReader struct {
+	reader io.Reader
+	budget *recursionBudget
+}
+
+func (b *budgetReader) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+var _ io.Writer = (*budgetWriter)(nil)
+
+// budgetWriter carries a recursionBudget through a write, so Nested mappers can find it.
+type budgetWriter struct {
+	writer io.Writer
+	budget *recursionBudget
+}
+
+func (b *budgetWriter) Write(p []byte) (int, error) {
+	return b.writer.Write(p)
+}
+
+// findReadBudget finds the recursionBudget for a RecursionBudget read, looking through readers that are used internally to bound, count, or annotate reads.
+func findReadBudget(r io.Reader) *recursionBudget {
+	for {
+		switch v := r.(type) {
+		case *budgetReader:
+			return v.budget
+		case *countingReader:
+			r = v.reader
+		case *io.LimitedReader:
+			r = v.R
+		case *annotatingReader:
+			r = v.reader
+		default:
+			return nil
+		}
+	}
+}
+
+// findWriteBudget finds the recursionBudget for a RecursionBudget write, looking through writers that are used internally to count or buffer writes.
+func findWriteBudget(w io.Writer) *recursionBudget {
+	for {
+		switch v := w.(type) {
+		case *budgetWriter:
+			return v.budget
+		case *countingWriter:
+			w = v.writer
+		case *transactionBuffer:
+			w = v.parent
+		default:
+			return nil
+		}
+	}
+}
+
+// RecursionBudget limits how deeply Nested mappers may be nested within m, which guards against stack exhaustion from untrusted input with arbitrarily deep recursive structures.
+// The budget is shared by every Nested mapper in the operation regardless of the types involved, so mutually recursive types (A contains B contains A) are limited as a whole.
+// Each Nested mapper takes one level from the budget while it's mapping, and ErrRecursionLimit is returned if more than limit Nested mappers are active at once.
+// Nested mappers must be mapped without intermediate buffering, like Coded or DERValue, to see the budget, although a Transactional write is seen through.
+// ErrInvalidRecursion is returned if limit is negative.
+func RecursionBudget(limit int, m Mapper) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	if limit < 0 {
+		return errMapping(fmt.Errorf("%w: got %d", ErrInvalidRecursion, limit))
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			budget := &recursionBudget{limit: limit, remaining: limit}
+			return m.Read(&budgetReader{reader: r, budget: budget}, endian)
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			budget := &recursionBudget{limit: limit, remaining: limit}
+			return m.Write(&budgetWriter{writer: w, budget: budget}, endian)
+		},
+	}
+}
+
+// Nested marks m as one level of a recursive structure, which takes a level from the enclosing RecursionBudget while m is mapping.
+// This should wrap the mapper for each recursive reference, such as the children of a tree node.
+// Outside of a RecursionBudget, this behaves exactly like m.
+func Nested(m Mapper) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			budget := findReadBudget(r)
+			if budget == nil {
+				return m.Read(r, endian)
+			}
+			return budget.enter(func() error {
+				return m.Read(r, endian)
+			})
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			budget := findWriteBudget(w)
+			if budget == nil {
+				return m.Write(w, endian)
+			}
+			return budget.enter(func() error {
+				return m.Write(w, endian)
+			})
+		},
+	}
+}
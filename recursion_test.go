@@ -0,0 +1,150 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type recNode struct {
+	val      uint8
+	count    uint8
+	children []recNode
+}
+
+func (n *recNode) mapper() Mapper {
+	return MapSequence(
+		Int(&n.val),
+		LenSlice(&n.children, &n.count, func(child *recNode) Mapper {
+			return Nested(child.mapper())
+		}),
+	)
+}
+
+// recChain returns a tree that's nested depth levels below the root.
+func recChain(depth int) recNode {
+	// Leaves are read with an empty slice of children.
+	root := recNode{val: uint8(depth), children: []recNode{}}
+	if depth > 0 {
+		root.children = []recNode{recChain(depth - 1)}
+		root.count = 1
+	}
+	return root
+}
+
+// recA and recB are mutually recursive, to make sure the budget is shared across types.
+type recA struct {
+	count uint8
+	bs    []recB
+}
+
+type recB struct {
+	count uint8
+	as    []recA
+}
+
+func (a *recA) mapper() Mapper {
+	return LenSlice(&a.bs, &a.count, func(b *recB) Mapper {
+		return Nested(b.mapper())
+	})
+}
+
+func (b *recB) mapper() Mapper {
+	return LenSlice(&b.as, &b.count, func(a *recA) Mapper {
+		return Nested(a.mapper())
+	})
+}
+
+func TestRecursionBudget(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		tree   = recChain(3)
+	)
+	assert.NoError(t, RecursionBudget(3, tree.mapper()).Write(&buf, endian))
+	data := buf.Bytes()
+	var read recNode
+	assert.NoError(t, RecursionBudget(3, read.mapper()).Read(bytes.NewReader(data), endian))
+	assert.Equal(t, tree, read)
+
+	read = recNode{}
+	assert.ErrorIs(t, RecursionBudget(2, read.mapper()).Read(bytes.NewReader(data), endian), ErrRecursionLimit)
+	buf.Reset()
+	assert.ErrorIs(t, RecursionBudget(2, tree.mapper()).Write(&buf, endian), ErrRecursionLimit)
+
+	read = recNode{}
+	assert.NoError(t, read.mapper().Read(bytes.NewReader(data), endian), "Nested should have no limit outside of a budget")
+	assert.Equal(t, tree, read)
+
+	buf.Reset()
+	assert.ErrorIs(t, RecursionBudget(2, Transactional(tree.mapper())).Write(&buf, endian), ErrRecursionLimit, "The budget should be found through a Transactional write")
+	assert.Equal(t, 0, buf.Len())
+	assert.NoError(t, RecursionBudget(3, Transactional(tree.mapper())).Write(&buf, endian))
+	read = recNode{}
+	assert.NoError(t, read.mapper().Read(&buf, endian))
+	assert.Equal(t, tree, read)
+}
+
+func TestRecursionBudget_Siblings(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		tree   = recNode{count: 3, children: []recNode{{val: 1, children: []recNode{}}, {val: 2, children: []recNode{}}, {val: 3, children: []recNode{}}}}
+	)
+	m := RecursionBudget(1, tree.mapper())
+	assert.NoError(t, m.Write(&buf, endian), "Siblings are at the same level, so they should each be allowed")
+	var read recNode
+	assert.NoError(t, RecursionBudget(1, read.mapper()).Read(&buf, endian))
+	assert.Equal(t, tree, read)
+}
+
+func TestRecursionBudget_MutualRecursion(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		// A contains B contains A contains B, which is 3 levels of nesting.
+		a = recA{count: 1, bs: []recB{{count: 1, as: []recA{{count: 1, bs: []recB{{as: []recA{}}}}}}}}
+	)
+	assert.NoError(t, RecursionBudget(3, a.mapper()).Write(&buf, endian))
+	data := buf.Bytes()
+	var read recA
+	assert.NoError(t, RecursionBudget(3, read.mapper()).Read(bytes.NewReader(data), endian))
+	assert.Equal(t, a, read)
+
+	read = recA{}
+	err := RecursionBudget(2, read.mapper()).Read(bytes.NewReader(data), endian)
+	assert.ErrorIs(t, err, ErrRecursionLimit)
+	assert.Contains(t, err.Error(), "2 levels")
+}
+
+func TestRecursionBudget_Wrapped(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		dump   strings.Builder
+		endian = binary.BigEndian
+		tree   = recChain(2)
+	)
+	assert.NoError(t, tree.mapper().Write(&buf, endian))
+	size := int64(buf.Len())
+
+	var read recNode
+	m := RecursionBudget(1, Annotate(ExactSize(Named("tree", read.mapper()), size), &dump))
+	assert.ErrorIs(t, m.Read(bytes.NewReader(buf.Bytes()), endian), ErrRecursionLimit, "The budget should be found through other internal readers")
+
+	read = recNode{}
+	dump.Reset()
+	m = Annotate(RecursionBudget(2, Named("tree", read.mapper())), &dump)
+	assert.NoError(t, m.Read(bytes.NewReader(buf.Bytes()), endian))
+	assert.Equal(t, tree, read)
+	assert.Contains(t, dump.String(), "tree", "Annotations should be found through the budget reader")
+
+	var outer, inner uint8
+	buf.Reset()
+	buf.Write([]byte{2, 5, 1, 2, 3, 4, 5})
+	m = ConsistentLen(&outer, RecursionBudget(1, ConsistentLen(&inner, FixedBytes(new([]byte), uint8(5)))))
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrExceedsParent, "Enclosing regions should be found through the budget reader")
+
+	assert.ErrorIs(t, RecursionBudget(-1, read.mapper()).Read(&buf, endian), ErrInvalidRecursion)
+}
@@ -0,0 +1,329 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrSeekRequired       = errors.New("an io.ReadSeeker is required")
+	ErrRefOutsideRoot     = errors.New("reference mapped outside of an ObjectTable root")
+	ErrRefType            = errors.New("referenced object has a different type")
+	ErrResolveOutsideRoot = errors.New("deferred resolution mapped outside of a DeferredResolver root")
+)
+
+// ObjectTable tracks objects that are referenced by their byte offset within a mapped region.
+// This is used with Ref to express internal pointers, as are found in many binary resource formats.
+//
+// All offsets are relative to the start of the region mapped with Root.
+// An offset of 0 is used to represent a nil reference, so the start of the region cannot itself be referenced.
+type ObjectTable struct {
+	// Write state
+	writer  *countingWriter
+	queued  []queuedObject
+	offsets map[any]int64
+	fixups  []refFixup
+
+	// Read state
+	reading bool
+	loads   []refLoad
+	objects map[int64]any
+}
+
+type queuedObject struct {
+	ptr    any
+	mapper Mapper
+}
+
+type refFixup struct {
+	pos   int64
+	ptr   any
+	patch func(buf []byte, offset int64, endian binary.ByteOrder) error
+}
+
+type refLoad struct {
+	offset int64
+	load   func(r io.Reader, endian binary.ByteOrder) (any, error)
+	assign func(obj any) error
+}
+
+// NewObjectTable creates a new, empty ObjectTable.
+func NewObjectTable() *ObjectTable {
+	return &ObjectTable{}
+}
+
+// Root maps a region that may contain references created with Ref.
+//
+// On write, the region is written with m, and each referenced object is appended after it before back-patching the references with the object offsets.
+// Each distinct object is only written once, regardless of how many times it's referenced.
+// Note that references must be written directly to the io.Writer given to Root's mapper, and not within a Mapper that buffers its output, since the reference position would be lost.
+//
+// On read, the region is read with m, and then each reference is resolved by seeking to its offset and reading the object.
+// The io.Reader passed to Read must be an io.ReadSeeker, and it will be positioned at the furthest point read once all references are resolved.
+// An io.ReaderAt with a Size method, like a memory-mapped file, may also be used, in which case the region starts at offset 0.
+func (t *ObjectTable) Root(m Mapper) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			rs := randomAccess(r)
+			if rs == nil {
+				return ErrSeekRequired
+			}
+			start, err := rs.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return err
+			}
+			t.reading, t.loads, t.objects = true, nil, map[int64]any{}
+			defer func() {
+				t.reading, t.loads, t.objects = false, nil, nil
+			}()
+			if err := m.Read(rs, endian); err != nil {
+				return err
+			}
+			end, err := rs.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return err
+			}
+			for i := 0; i < len(t.loads); i++ {
+				load := t.loads[i]
+				obj, ok := t.objects[load.offset]
+				if !ok {
+					if _, err := rs.Seek(start+load.offset, io.SeekStart); err != nil {
+						return err
+					}
+					obj, err = load.load(rs, endian)
+					if err != nil {
+						return err
+					}
+					t.objects[load.offset] = obj
+					pos, err := rs.Seek(0, io.SeekCurrent)
+					if err != nil {
+						return err
+					}
+					if pos > end {
+						end = pos
+					}
+				}
+				if err := load.assign(obj); err != nil {
+					return err
+				}
+			}
+			_, err = rs.Seek(end, io.SeekStart)
+			return err
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			t.writer, t.queued, t.offsets, t.fixups = &countingWriter{writer: &buf}, nil, map[any]int64{}, nil
+			defer func() {
+				t.writer, t.queued, t.offsets, t.fixups = nil, nil, nil, nil
+			}()
+			if err := m.Write(t.writer, endian); err != nil {
+				return err
+			}
+			for i := 0; i < len(t.queued); i++ {
+				obj := t.queued[i]
+				t.offsets[obj.ptr] = t.writer.n
+				if err := obj.mapper.Write(t.writer, endian); err != nil {
+					return err
+				}
+			}
+			out := buf.Bytes()
+			for _, fix := range t.fixups {
+				if err := fix.patch(out[fix.pos:], t.offsets[fix.ptr], endian); err != nil {
+					return err
+				}
+			}
+			_, err := buf.WriteTo(w)
+			return err
+		},
+	)
+}
+
+// Ref maps a pointer to an object as an offset of size S within a region mapped with the ObjectTable's Root.
+// The mapVal function is used to map the referenced object itself.
+// A nil pointer is written as a zero offset, and a zero offset is read as a nil pointer.
+// References to the same offset will be resolved to the same pointer on read.
+func Ref[S SizeType, T any](table *ObjectTable, target **T, mapVal func(*T) Mapper) Mapper {
+	if table == nil || target == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if !table.reading {
+				return ErrRefOutsideRoot
+			}
+			var offset S
+			if err := Size(&offset).Read(r, endian); err != nil {
+				return err
+			}
+			if offset == 0 {
+				*target = nil
+				return nil
+			}
+			table.loads = append(table.loads, refLoad{
+				offset: int64(offset),
+				load: func(r io.Reader, endian binary.ByteOrder) (any, error) {
+					obj := new(T)
+					if err := mapVal(obj).Read(r, endian); err != nil {
+						return nil, err
+					}
+					return obj, nil
+				},
+				assign: func(obj any) error {
+					typed, ok := obj.(*T)
+					if !ok {
+						return fmt.Errorf("%w: object at offset %d is %T, not %T", ErrRefType, offset, obj, *target)
+					}
+					*target = typed
+					return nil
+				},
+			})
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			if table.writer == nil {
+				return ErrRefOutsideRoot
+			}
+			var placeholder S
+			if *target == nil {
+				return Size(&placeholder).Write(w, endian)
+			}
+			ptr := *target
+			if _, ok := table.offsets[ptr]; !ok {
+				table.offsets[ptr] = 0
+				table.queued = append(table.queued, queuedObject{ptr: ptr, mapper: mapVal(ptr)})
+			}
+			table.fixups = append(table.fixups, refFixup{
+				pos: table.writer.n,
+				ptr: ptr,
+				patch: func(buf []byte, offset int64, endian binary.ByteOrder) error {
+					size, err := toSize[S](int(offset))
+					if err != nil {
+						return err
+					}
+					var patched bytes.Buffer
+					if err := Size(&size).Write(&patched, endian); err != nil {
+						return err
+					}
+					copy(buf, patched.Bytes())
+					return nil
+				},
+			})
+			return Size(&placeholder).Write(w, endian)
+		},
+	)
+}
+
+// DeferredResolver collects resolution steps that must wait until a whole structure has been read, such as resolving offsets into a symbol or string table at the end of a file.
+// This is used with DeferredResolve.
+type DeferredResolver struct {
+	reading bool
+	pending []func() error
+}
+
+// NewDeferredResolver creates a new DeferredResolver.
+func NewDeferredResolver() *DeferredResolver {
+	return &DeferredResolver{}
+}
+
+// Root maps a structure that may contain DeferredResolve mappers.
+// On read, the structure is read with m, and then each deferred resolution is run in the order it was encountered.
+// On write, m is used as-is.
+func (d *DeferredResolver) Root(m Mapper) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			d.reading, d.pending = true, nil
+			defer func() {
+				d.reading, d.pending = false, nil
+			}()
+			if err := m.Read(r, endian); err != nil {
+				return err
+			}
+			for _, resolve := range d.pending {
+				if err := resolve(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		write: m.Write,
+	}
+}
+
+// DeferredResolve maps an offset as a uint32, and resolves it into resolved with the resolver function once the DeferredResolver's Root has read the whole structure.
+// This allows offsets into a table that appears later in the source to be resolved after the table has been read.
+// Since resolution happens after the read completes, resolved must not be a temporary copy, so slices of values with deferred fields should use pointer elements.
+// On write, the offset is written as-is, so it's the caller's responsibility to ensure that it's consistent with resolved.
+func DeferredResolve[T any](d *DeferredResolver, offset *uint32, resolved *T, resolver func(offset uint32) (T, error)) Mapper {
+	if d == nil || offset == nil || resolved == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if !d.reading {
+				return ErrResolveOutsideRoot
+			}
+			if err := Int(offset).Read(r, endian); err != nil {
+				return err
+			}
+			off := *offset
+			d.pending = append(d.pending, func() error {
+				val, err := resolver(off)
+				if err != nil {
+					return err
+				}
+				*resolved = val
+				return nil
+			})
+			return nil
+		},
+		write: Int(offset).Write,
+	}
+}
+
+// InlineOrRef maps a value that is either stored inline or referenced by an offset into a heap, such as a string that is stored inline when it's small enough.
+// The representation is selected by a leading boolean flag, which is read into or written from isInline.
+// On read, an inline value is read with the Mapper returned from inlineMapper, and otherwise a uint32 offset is read into offset and immediately resolved with refResolver.
+// The heap must already be available to refResolver when this is read. If the heap appears later in the source, then DeferredResolve should be used for the reference instead.
+// On write, an inline value is written with inlineMapper, and otherwise offset is written as-is, so it's the caller's responsibility to ensure that it's consistent with target.
+func InlineOrRef[T any](target *T, isInline *bool, offset *uint32, inlineMapper func(*T) Mapper, refResolver func(offset uint32) (T, error)) Mapper {
+	if target == nil || isInline == nil || offset == nil || inlineMapper == nil || refResolver == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Bool(isInline).Read(r, endian); err != nil {
+				return err
+			}
+			if *isInline {
+				return inlineMapper(target).Read(r, endian)
+			}
+			if err := Int(offset).Read(r, endian); err != nil {
+				return err
+			}
+			val, err := refResolver(*offset)
+			if err != nil {
+				return err
+			}
+			*target = val
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if err := Bool(isInline).Write(w, endian); err != nil {
+				return err
+			}
+			if *isInline {
+				return inlineMapper(target).Write(w, endian)
+			}
+			return Int(offset).Write(w, endian)
+		},
+	}
+}
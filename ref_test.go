@@ -0,0 +1,177 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type refNode struct {
+	val  uint16
+	next *refNode
+}
+
+func (n *refNode) mapper(table *ObjectTable) Mapper {
+	return MapSequence(
+		Int(&n.val),
+		Ref[uint8](table, &n.next, func(next *refNode) Mapper {
+			return next.mapper(table)
+		}),
+	)
+}
+
+func TestRef(t *testing.T) {
+	type root struct {
+		magic  uint16
+		first  *refNode
+		second *refNode
+		none   *refNode
+	}
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		table  = NewObjectTable()
+		tail   = &refNode{val: 2}
+		data   = root{
+			magic:  0xBEEF,
+			first:  &refNode{val: 1, next: tail},
+			second: tail,
+		}
+	)
+	mapNode := func(n *refNode) Mapper {
+		return n.mapper(table)
+	}
+	m := table.Root(MapSequence(
+		Int(&data.magic),
+		Ref[uint8](table, &data.first, mapNode),
+		Ref[uint8](table, &data.second, mapNode),
+		Ref[uint8](table, &data.none, mapNode),
+	))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{
+		0xBE, 0xEF, 0x05, 0x08, 0x00, // Root
+		0x00, 0x01, 0x08, // First node
+		0x00, 0x02, 0x00, // Tail node
+	}, buf.Bytes())
+
+	buf.WriteByte(0xFF)
+	data = root{}
+	r := bytes.NewReader(buf.Bytes())
+	assert.NoError(t, m.Read(r, endian))
+	assert.Equal(t, uint16(0xBEEF), data.magic)
+	assert.Equal(t, uint16(1), data.first.val)
+	assert.Equal(t, uint16(2), data.second.val)
+	assert.Same(t, data.first.next, data.second)
+	assert.Nil(t, data.second.next)
+	assert.Nil(t, data.none)
+	assert.Equal(t, 1, r.Len(), "Reader should be positioned after the last object")
+
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrSeekRequired)
+	assert.ErrorIs(t, Ref[uint8](table, &data.first, mapNode).Write(&buf, endian), ErrRefOutsideRoot)
+}
+
+func TestDeferredResolve(t *testing.T) {
+	type symbol struct {
+		nameOffset uint32
+		name       string
+		value      uint16
+	}
+	var (
+		buf      bytes.Buffer
+		endian   = binary.BigEndian
+		resolver = NewDeferredResolver()
+		symbols  []*symbol
+		strtab   []byte
+		tabLen   uint8
+	)
+	lookup := func(offset uint32) (string, error) {
+		if int(offset) >= len(strtab) {
+			return "", ErrOutOfBounds
+		}
+		end := bytes.IndexByte(strtab[offset:], 0)
+		if end < 0 {
+			return "", ErrOutOfBounds
+		}
+		return string(strtab[offset : int(offset)+end]), nil
+	}
+	m := resolver.Root(MapSequence(
+		Slice(&symbols, uint8(2), func(s **symbol) Mapper {
+			if *s == nil {
+				*s = new(symbol)
+			}
+			return MapSequence(
+				DeferredResolve(resolver, &(*s).nameOffset, &(*s).name, lookup),
+				Int(&(*s).value),
+			)
+		}),
+		LenBytes(&strtab, &tabLen),
+	))
+	data := []byte{
+		0, 0, 0, 0, 0, 1,
+		0, 0, 0, 5, 0, 2,
+		10, 'm', 'a', 'i', 'n', 0, 'e', 'x', 'i', 't', 0,
+	}
+	buf.Write(data)
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []*symbol{
+		{nameOffset: 0, name: "main", value: 1},
+		{nameOffset: 5, name: "exit", value: 2},
+	}, symbols)
+
+	buf.Reset()
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, data, buf.Bytes())
+
+	buf.Reset()
+	buf.Write([]byte{0, 0, 0, 20, 0, 1, 0, 0, 0, 0, 0, 2, 0})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrOutOfBounds)
+
+	var (
+		offset uint32
+		name   string
+	)
+	buf.Reset()
+	buf.Write([]byte{0, 0, 0, 0})
+	assert.ErrorIs(t, DeferredResolve(resolver, &offset, &name, lookup).Read(&buf, endian), ErrResolveOutsideRoot)
+}
+
+func TestInlineOrRef(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		endian   = binary.BigEndian
+		heap     = []byte("a long string\x00another long string\x00")
+		heapLen  uint16
+		names    = []string{"short", "another long string"}
+		inline   = []bool{true, false}
+		offsets  = []uint32{0, 14}
+		resolver = func(offset uint32) (string, error) {
+			var s string
+			if offset >= uint32(len(heap)) {
+				return "", ErrOutOfBounds
+			}
+			err := NullTermString(&s).Read(bytes.NewReader(heap[offset:]), endian)
+			return s, err
+		}
+	)
+	m := MapSequence(
+		LenBytes(&heap, &heapLen),
+		InlineOrRef(&names[0], &inline[0], &offsets[0], NullTermString, resolver),
+		InlineOrRef(&names[1], &inline[1], &offsets[1], NullTermString, resolver),
+	)
+	heapLen = uint16(len(heap))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "short\x00", string(buf.Bytes()[2+len(heap)+1:2+len(heap)+7]))
+	assert.Equal(t, []byte{0, 0, 0, 0, 14}, buf.Bytes()[buf.Len()-5:])
+
+	names[0], names[1] = "", ""
+	inline[0], inline[1] = false, true
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []string{"short", "another long string"}, names)
+	assert.Equal(t, []bool{true, false}, inline)
+
+	buf.Reset()
+	offsets[1] = 100
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrOutOfBounds)
+}
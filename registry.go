@@ -0,0 +1,127 @@
+package bin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var (
+	ErrDuplicateRegistration = errors.New("tag or type is already registered")
+	ErrUnregisteredType      = errors.New("type is not registered")
+)
+
+type registryEntry struct {
+	tag   uint16
+	read  func(r io.Reader, endian binary.ByteOrder) (any, error)
+	write func(w io.Writer, endian binary.ByteOrder, val any) error
+}
+
+// Registry associates a uint16 type tag with a concrete type and the Mapper used for it, so values of different types can be mapped through a single `any`.
+// Types are registered with Register, and the Registry is used with Tagged and TaggedSlice.
+type Registry struct {
+	byTag  map[uint16]*registryEntry
+	byType map[reflect.Type]*registryEntry
+}
+
+// NewRegistry creates a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		byTag:  map[uint16]*registryEntry{},
+		byType: map[reflect.Type]*registryEntry{},
+	}
+}
+
+// Register associates tag with the type T in the Registry, which will be mapped with the Mapper returned from mapVal.
+// ErrDuplicateRegistration is returned if either the tag or the type is already registered.
+func Register[T any](reg *Registry, tag uint16, mapVal func(*T) Mapper) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if _, ok := reg.byTag[tag]; ok {
+		return fmt.Errorf("%w: tag %d", ErrDuplicateRegistration, tag)
+	}
+	if _, ok := reg.byType[typ]; ok {
+		return fmt.Errorf("%w: type %s", ErrDuplicateRegistration, typ)
+	}
+	entry := &registryEntry{
+		tag: tag,
+		read: func(r io.Reader, endian binary.ByteOrder) (any, error) {
+			var val T
+			if err := mapVal(&val).Read(r, endian); err != nil {
+				return nil, err
+			}
+			return val, nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder, val any) error {
+			typed := val.(T)
+			return mapVal(&typed).Write(w, endian)
+		},
+	}
+	reg.byTag[tag] = entry
+	reg.byType[typ] = entry
+	return nil
+}
+
+// Tagged maps a single value of any type registered in reg, prefixed with its uint16 type tag.
+// On read, ErrUnknownVariant is returned if the tag is not registered.
+// On write, ErrUnregisteredType is returned if the value's concrete type is not registered.
+func Tagged(target *any, reg *Registry) Mapper {
+	if target == nil || reg == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var tag uint16
+			if err := Int(&tag).Read(r, endian); err != nil {
+				return err
+			}
+			entry, ok := reg.byTag[tag]
+			if !ok {
+				return fmt.Errorf("%w: tag %d is not registered", ErrUnknownVariant, tag)
+			}
+			val, err := entry.read(r, endian)
+			if err != nil {
+				return err
+			}
+			*target = val
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			entry, ok := reg.byType[reflect.TypeOf(*target)]
+			if !ok {
+				return fmt.Errorf("%w: %T", ErrUnregisteredType, *target)
+			}
+			tag := entry.tag
+			if err := Int(&tag).Write(w, endian); err != nil {
+				return err
+			}
+			return entry.write(w, endian, *target)
+		},
+	)
+}
+
+// TaggedSlice maps a heterogeneous slice prefixed with its element count, where each element is mapped with Tagged.
+// On write, count is set to the length of the slice.
+func TaggedSlice[S SizeType](target *[]any, count *S, reg *Registry) Mapper {
+	if target == nil || count == nil || reg == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			return LenSlice(target, count, func(e *any) Mapper {
+				return Tagged(e, reg)
+			}).Read(r, endian)
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			length, err := toSize[S](len(*target))
+			if err != nil {
+				return err
+			}
+			*count = length
+			return LenSlice(target, count, func(e *any) Mapper {
+				return Tagged(e, reg)
+			}).Write(w, endian)
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTaggedSlice(t *testing.T) {
+	type point struct {
+		x, y int16
+	}
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		reg    = NewRegistry()
+		count  uint8
+		values = []any{uint32(7), "hi", point{x: 1, y: -1}}
+	)
+	assert.NoError(t, Register(reg, 1, func(v *uint32) Mapper { return Int(v) }))
+	assert.NoError(t, Register(reg, 2, func(v *string) Mapper { return NullTermString(v) }))
+	assert.NoError(t, Register(reg, 3, func(p *point) Mapper { return MapSequence(Int(&p.x), Int(&p.y)) }))
+	assert.ErrorIs(t, Register(reg, 1, func(v *bool) Mapper { return Bool(v) }), ErrDuplicateRegistration)
+	assert.ErrorIs(t, Register(reg, 4, func(v *uint32) Mapper { return Int(v) }), ErrDuplicateRegistration)
+
+	m := TaggedSlice(&values, &count, reg)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint8(3), count)
+	assert.Equal(t, []byte{
+		3,
+		0, 1, 0, 0, 0, 7,
+		0, 2, 'h', 'i', 0,
+		0, 3, 0, 1, 0xFF, 0xFF,
+	}, buf.Bytes())
+
+	values = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []any{uint32(7), "hi", point{x: 1, y: -1}}, values)
+
+	values = []any{3.5}
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrUnregisteredType)
+
+	buf.Reset()
+	buf.Write([]byte{1, 0, 9})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrUnknownVariant)
+}
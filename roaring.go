@@ -0,0 +1,222 @@
+package bin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+var (
+	ErrInvalidRoaring = errors.New("invalid roaring bitmap")
+)
+
+const (
+	roaringCookieNoRuns   = 12346
+	roaringCookieRuns     = 12347
+	roaringNoOffsetLimit  = 4
+	roaringArrayMaxCard   = 4096
+	roaringBitmapWords    = 1024
+	roaringMaxContainers  = 1 << 16
+	roaringContainerRange = 1 << 16
+)
+
+// RoaringBitmap maps a set of uint32 values in the portable Roaring bitmap serialization format, which is widely used for compressed bitmaps in databases and search engines.
+// The format is always little-endian, regardless of the endian policy.
+// On read, array, bitmap, and run containers are supported, and target is set to the sorted values in the set.
+// On write, the values in target are sorted and de-duplicated, and written with array and bitmap containers.
+// ErrInvalidRoaring is returned on read if the serialized data is malformed, including array values that aren't strictly increasing, and runs that aren't ascending and non-overlapping.
+func RoaringBitmap(target *[]uint32) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			vals, err := readRoaring(r)
+			if err != nil {
+				return err
+			}
+			*target = vals
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			return writeRoaring(w, *target)
+		},
+	)
+}
+
+func readRoaring(r io.Reader) ([]uint32, error) {
+	var (
+		le     = binary.LittleEndian
+		cookie uint32
+		size   uint32
+		isRun  []byte
+	)
+	if err := Int(&cookie).Read(r, le); err != nil {
+		return nil, err
+	}
+	switch {
+	case cookie == roaringCookieNoRuns:
+		if err := Int(&size).Read(r, le); err != nil {
+			return nil, err
+		}
+		if size > roaringMaxContainers {
+			return nil, fmt.Errorf("%w: %d containers", ErrInvalidRoaring, size)
+		}
+	case cookie&0xFFFF == roaringCookieRuns:
+		size = cookie>>16 + 1
+		if err := FixedBytes(&isRun, (size+7)/8).Read(r, le); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown cookie 0x%08X", ErrInvalidRoaring, cookie)
+	}
+	var (
+		keys  = make([]uint16, size)
+		cards = make([]int, size)
+	)
+	for i := range keys {
+		var card uint16
+		if err := MapSequence(Int(&keys[i]), Int(&card)).Read(r, le); err != nil {
+			return nil, err
+		}
+		if i > 0 && keys[i] <= keys[i-1] {
+			return nil, fmt.Errorf("%w: container keys are not sorted", ErrInvalidRoaring)
+		}
+		cards[i] = int(card) + 1
+	}
+	if isRun == nil || size >= roaringNoOffsetLimit {
+		// Offsets are only needed for random access, and the containers are read sequentially.
+		if _, err := io.CopyN(io.Discard, r, int64(size)*4); err != nil {
+			return nil, err
+		}
+	}
+	var vals []uint32
+	for i, key := range keys {
+		high := uint32(key) << 16
+		switch {
+		case isRun != nil && isRun[i/8]&(1<<(i%8)) != 0:
+			var (
+				numRuns uint16
+				card    int
+				// The end of the previous run, or -1 so the first run may start at 0.
+				prevEnd = -1
+			)
+			if err := Int(&numRuns).Read(r, le); err != nil {
+				return nil, err
+			}
+			for j := uint16(0); j < numRuns; j++ {
+				var start, length uint16
+				if err := MapSequence(Int(&start), Int(&length)).Read(r, le); err != nil {
+					return nil, err
+				}
+				if int(start)+int(length) >= roaringContainerRange {
+					return nil, fmt.Errorf("%w: run exceeds the container range", ErrInvalidRoaring)
+				}
+				if int(start) <= prevEnd {
+					return nil, fmt.Errorf("%w: run %d in container %d overlaps or precedes the previous run", ErrInvalidRoaring, j, i)
+				}
+				prevEnd = int(start) + int(length)
+				// Runs that are ascending and in range can't exceed the range of a container, but this makes the limit explicit.
+				if card += int(length) + 1; card > roaringContainerRange {
+					return nil, fmt.Errorf("%w: container %d has more than %d values", ErrInvalidRoaring, i, roaringContainerRange)
+				}
+				for v := int(start); v <= int(start)+int(length); v++ {
+					vals = append(vals, high|uint32(v))
+				}
+			}
+		case cards[i] > roaringArrayMaxCard:
+			words := make([]uint64, roaringBitmapWords)
+			if err := Slice(&words, uint16(roaringBitmapWords), Int[uint64]).Read(r, le); err != nil {
+				return nil, err
+			}
+			for w, word := range words {
+				for word != 0 {
+					vals = append(vals, high|uint32(w*64+bits.TrailingZeros64(word)))
+					word &= word - 1
+				}
+			}
+		default:
+			var lows []uint16
+			if err := Slice(&lows, uint16(cards[i]), Int[uint16]).Read(r, le); err != nil {
+				return nil, err
+			}
+			for j, low := range lows {
+				if j > 0 && low <= lows[j-1] {
+					return nil, fmt.Errorf("%w: array container %d values are not strictly increasing", ErrInvalidRoaring, i)
+				}
+				vals = append(vals, high|uint32(low))
+			}
+		}
+	}
+	return vals, nil
+}
+
+func writeRoaring(w io.Writer, vals []uint32) error {
+	le := binary.LittleEndian
+	sorted := make([]uint32, len(vals))
+	copy(sorted, vals)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	type container struct {
+		key  uint16
+		lows []uint16
+	}
+	var containers []container
+	for i, v := range sorted {
+		if i > 0 && v == sorted[i-1] {
+			continue
+		}
+		key := uint16(v >> 16)
+		if len(containers) == 0 || containers[len(containers)-1].key != key {
+			containers = append(containers, container{key: key})
+		}
+		c := &containers[len(containers)-1]
+		c.lows = append(c.lows, uint16(v))
+	}
+
+	var (
+		cookie = uint32(roaringCookieNoRuns)
+		size   = uint32(len(containers))
+		// The offset of the first container is after the cookie, size, descriptive header, and offset header.
+		offset = 8 + 8*size
+	)
+	if err := MapSequence(Int(&cookie), Int(&size)).Write(w, le); err != nil {
+		return err
+	}
+	for _, c := range containers {
+		key, card := c.key, uint16(len(c.lows)-1)
+		if err := MapSequence(Int(&key), Int(&card)).Write(w, le); err != nil {
+			return err
+		}
+	}
+	for _, c := range containers {
+		if err := Int(&offset).Write(w, le); err != nil {
+			return err
+		}
+		if len(c.lows) > roaringArrayMaxCard {
+			offset += roaringBitmapWords * 8
+		} else {
+			offset += uint32(len(c.lows)) * 2
+		}
+	}
+	for _, c := range containers {
+		if len(c.lows) <= roaringArrayMaxCard {
+			if err := Slice(&c.lows, uint16(len(c.lows)), Int[uint16]).Write(w, le); err != nil {
+				return err
+			}
+			continue
+		}
+		words := make([]uint64, roaringBitmapWords)
+		for _, low := range c.lows {
+			words[low/64] |= 1 << (low % 64)
+		}
+		if err := Slice(&words, uint16(roaringBitmapWords), Int[uint64]).Write(w, le); err != nil {
+			return err
+		}
+	}
+	return nil
+}
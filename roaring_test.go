@@ -0,0 +1,124 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRoaringBitmap(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		vals   = []uint32{5, 1, 1 << 20, 3, 5}
+	)
+	m := RoaringBitmap(&vals)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{
+		0x3A, 0x30, 0, 0, // cookie
+		2, 0, 0, 0, // container count
+		0, 0, 2, 0, // key 0, cardinality 3
+		0x10, 0, 0, 0, // key 16, cardinality 1
+		24, 0, 0, 0, // offset of the first container
+		30, 0, 0, 0, // offset of the second container
+		1, 0, 3, 0, 5, 0,
+		0, 0,
+	}, buf.Bytes(), "Output should be little-endian regardless of the endian policy")
+
+	vals = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []uint32{1, 3, 5, 1 << 20}, vals)
+
+	var expected []uint32
+	for i := uint32(0); i < 10000; i += 2 {
+		expected = append(expected, 7<<16|i)
+	}
+	expected = append(expected, 0xFFFFFFFF)
+	vals = append([]uint32{}, expected...)
+	buf.Reset()
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, 8+8*2+8192+2, buf.Len(), "A bitmap container should be used for high cardinality")
+	vals = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, expected, vals)
+}
+
+func TestRoaringBitmap_Runs(t *testing.T) {
+	var (
+		endian = binary.LittleEndian
+		vals   []uint32
+	)
+	data := []byte{
+		0x3B, 0x30, 1, 0, // cookie with runs, 2 containers
+		0x01,       // the first container is a run container
+		0, 0, 5, 0, // key 0, cardinality 6
+		1, 0, 1, 0, // key 1, cardinality 2
+		2, 0, // 2 runs
+		10, 0, 2, 0, // 10-12
+		20, 0, 2, 0, // 20-22
+		7, 0, 9, 0, // array container
+	}
+	assert.NoError(t, RoaringBitmap(&vals).Read(bytes.NewReader(data), endian))
+	assert.Equal(t, []uint32{10, 11, 12, 20, 21, 22, 1<<16 | 7, 1<<16 | 9}, vals)
+
+	data[0] = 0
+	assert.ErrorIs(t, RoaringBitmap(&vals).Read(bytes.NewReader(data), endian), ErrInvalidRoaring)
+}
+
+func TestRoaringBitmap_Malformed(t *testing.T) {
+	var (
+		endian = binary.LittleEndian
+		vals   []uint32
+	)
+	tests := map[string][]byte{
+		"Unsorted array": {
+			0x3A, 0x30, 0, 0, 1, 0, 0, 0, // cookie, 1 container
+			0, 0, 2, 0, // key 0, cardinality 3
+			12, 0, 0, 0, // offset
+			1, 0, 5, 0, 3, 0,
+		},
+		"Duplicate array values": {
+			0x3A, 0x30, 0, 0, 1, 0, 0, 0, // cookie, 1 container
+			0, 0, 1, 0, // key 0, cardinality 2
+			12, 0, 0, 0, // offset
+			5, 0, 5, 0,
+		},
+		"Overlapping runs": {
+			0x3B, 0x30, 0, 0, // cookie with runs, 1 container
+			0x01,       // run container
+			0, 0, 0, 0, // key 0
+			2, 0, // 2 runs
+			10, 0, 5, 0, // 10-15
+			12, 0, 1, 0, // 12-13
+		},
+		"Descending runs": {
+			0x3B, 0x30, 0, 0, // cookie with runs, 1 container
+			0x01,       // run container
+			0, 0, 0, 0, // key 0
+			2, 0, // 2 runs
+			20, 0, 0, 0, // 20
+			10, 0, 0, 0, // 10
+		},
+		"Repeated full runs": {
+			0x3B, 0x30, 0, 0, // cookie with runs, 1 container
+			0x01,       // run container
+			0, 0, 0, 0, // key 0
+			2, 0, // 2 runs
+			0, 0, 0xFF, 0xFF, // 0-65535
+			0, 0, 0xFF, 0xFF, // 0-65535 again
+		},
+		"Run out of range": {
+			0x3B, 0x30, 0, 0, // cookie with runs, 1 container
+			0x01,       // run container
+			0, 0, 0, 0, // key 0
+			1, 0, // 1 run
+			0xFF, 0xFF, 1, 0, // 65535-65536
+		},
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, RoaringBitmap(&vals).Read(bytes.NewReader(data), endian), ErrInvalidRoaring)
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package bin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	ErrOffsetOrder      = errors.New("end offset is before start offset")
+	ErrWriterAtRequired = errors.New("an io.WriterAt or io.WriteSeeker is required")
+)
+
+var _ error = (*OffsetError)(nil)
+
+// OffsetError reports the offset that was reached in a source before an error occurred.
+type OffsetError struct {
+	// Offset is the absolute offset reached before the error occurred.
+	Offset int64
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("error at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *OffsetError) Unwrap() error {
+	return e.Err
+}
+
+// SeekTo maps a record with m, and then seeks to the absolute offset in nextOffset.
+// This is useful for formats where each record declares the offset of the next record, and there may be slack space between records.
+// The nextOffset value would typically be populated as part of reading the record with m.
+//
+// The given io.ReadSeeker must be the same source that is passed to Read.
+// On write, the record will be written with m, and if the io.Writer also implements io.Seeker, it will be positioned at nextOffset.
+func SeekTo(ra io.ReadSeeker, nextOffset *int64, m Mapper) Mapper {
+	if ra == nil || nextOffset == nil || m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if err := m.Read(r, endian); err != nil {
+				return err
+			}
+			_, err := ra.Seek(*nextOffset, io.SeekStart)
+			return err
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			if err := m.Write(w, endian); err != nil {
+				return err
+			}
+			if ws, ok := w.(io.Seeker); ok {
+				if _, err := ws.Seek(*nextOffset, io.SeekStart); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// Resumable maps m starting at the absolute offset in offset, which allows processing a large source incrementally, across multiple calls or even process restarts.
+// On read, rs is positioned at offset before reading with m, and offset is advanced past the bytes read if m succeeds.
+// If m returns an error, then offset is left unchanged so the read may be retried, and an *OffsetError is returned that reports how far reading progressed.
+//
+// The given io.ReadSeeker must be the same source that is passed to Read.
+// On write, the io.Writer will be positioned at offset first if it implements io.Seeker, and offset is advanced past the bytes written if m succeeds.
+func Resumable(rs io.ReadSeeker, offset *int64, m Mapper) Mapper {
+	if rs == nil || offset == nil || m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(_ io.Reader, endian binary.ByteOrder) error {
+			if _, err := rs.Seek(*offset, io.SeekStart); err != nil {
+				return err
+			}
+			cr := &countingReader{reader: rs}
+			if err := m.Read(cr, endian); err != nil {
+				return &OffsetError{Offset: *offset + cr.n, Err: err}
+			}
+			*offset += cr.n
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			if ws, ok := w.(io.Seeker); ok {
+				if _, err := ws.Seek(*offset, io.SeekStart); err != nil {
+					return err
+				}
+			}
+			cw := &countingWriter{writer: w}
+			if err := m.Write(cw, endian); err != nil {
+				return &OffsetError{Offset: *offset + cw.n, Err: err}
+			}
+			*offset += cw.n
+			return nil
+		},
+	)
+}
+
+// OffsetDeltaBytes maps a byte slice whose length is implied by the difference between two offsets, as is the case with sections delimited by adjacent entries in an offset table.
+// On read, endOffset - startOffset bytes are read into buf, and ErrOffsetOrder is returned if endOffset is less than startOffset.
+// The offsets would typically be populated by reading the offset table first.
+//
+// On write, startOffset is set to the current position if the io.Writer implements io.Seeker, and endOffset is set to startOffset plus the length of buf.
+// This allows the offset table to be back-patched, or written in a second pass, once all sections have been written.
+func OffsetDeltaBytes(buf *[]byte, startOffset, endOffset *uint32) Mapper {
+	if buf == nil || startOffset == nil || endOffset == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if *endOffset < *startOffset {
+				return fmt.Errorf("%w: start %d, end %d", ErrOffsetOrder, *startOffset, *endOffset)
+			}
+			return FixedBytes(buf, *endOffset-*startOffset).Read(r, endian)
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			if ws, ok := w.(io.Seeker); ok {
+				pos, err := ws.Seek(0, io.SeekCurrent)
+				if err != nil {
+					return err
+				}
+				start, err := toSize[uint32](int(pos))
+				if err != nil {
+					return err
+				}
+				*startOffset = start
+			}
+			length, err := toSize[uint32](len(*buf))
+			if err != nil {
+				return err
+			}
+			end, err := checkedAdd(*startOffset, length)
+			if err != nil {
+				return err
+			}
+			*endOffset = end
+			return FixedBytes(buf, length).Write(w, endian)
+		},
+	)
+}
+
+// sizedReaderAt is an io.ReaderAt that knows its size, like a memory-mapped region, *bytes.Reader, or *io.SectionReader.
+type sizedReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// randomAccess returns r as an io.ReadSeeker for mappers that need to seek within the source.
+// An io.ReaderAt that knows its size is also accepted, and is read with an io.SectionReader starting at offset 0, so it doesn't need to implement io.Seeker.
+// Nil is returned if r supports neither.
+func randomAccess(r io.Reader) io.ReadSeeker {
+	switch r := r.(type) {
+	case io.ReadSeeker:
+		return r
+	case sizedReaderAt:
+		return io.NewSectionReader(r, 0, r.Size())
+	default:
+		return nil
+	}
+}
+
+var _ io.Writer = (*offsetWriter)(nil)
+
+// offsetWriter writes sequentially to an io.WriterAt, starting at an absolute offset.
+type offsetWriter struct {
+	writer io.WriterAt
+	offset int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.writer.WriteAt(p, o.offset)
+	o.offset += int64(n)
+	return n, err
+}
+
+// AtOffset maps m at the absolute offset in a random access source, without changing the position of the stream being mapped.
+// This is useful for formats with an index of offsets, since fields can be read directly from their location.
+//
+// On read, m reads from an io.SectionReader over ra starting at offset, so the io.Reader passed to Read is not used.
+// Since an io.SectionReader is also an io.ReadSeeker and an io.ReaderAt, random access mappers such as SeekTo, Resumable, ObjectTable, and nested AtOffset mappers work transparently within m.
+// Any io.ReaderAt may be used, so a memory-mapped file can be read without a system call per field.
+// If ra has a Size method, like *bytes.Reader or *io.SectionReader, then the section ends at that size, so mappers that seek relative to the end of the source, such as TrailingLenBytes and TrailerIndex, work within m.
+// ErrOutOfBounds is returned if offset is negative, or past the end of a sized source.
+//
+// On write, m is written at offset if the io.Writer implements io.WriterAt.
+// Otherwise, if it implements io.WriteSeeker, it's positioned at offset for m, and then returned to its original position.
+// ErrWriterAtRequired is returned if neither is implemented.
+func AtOffset(ra io.ReaderAt, offset *int64, m Mapper) Mapper {
+	if ra == nil || offset == nil || m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(_ io.Reader, endian binary.ByteOrder) error {
+			if *offset < 0 {
+				return fmt.Errorf("%w: negative offset %d", ErrOutOfBounds, *offset)
+			}
+			size := int64(math.MaxInt64)
+			if sized, ok := ra.(sizedReaderAt); ok {
+				size = sized.Size()
+				if *offset > size {
+					return fmt.Errorf("%w: offset %d is past the end of the %d byte source", ErrOutOfBounds, *offset, size)
+				}
+			}
+			return m.Read(io.NewSectionReader(ra, *offset, size-*offset), endian)
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			switch w := w.(type) {
+			case io.WriterAt:
+				return m.Write(&offsetWriter{writer: w, offset: *offset}, endian)
+			case io.WriteSeeker:
+				pos, err := w.Seek(0, io.SeekCurrent)
+				if err != nil {
+					return err
+				}
+				if _, err := w.Seek(*offset, io.SeekStart); err != nil {
+					return err
+				}
+				if err := m.Write(w, endian); err != nil {
+					return err
+				}
+				_, err = w.Seek(pos, io.SeekStart)
+				return err
+			default:
+				return ErrWriterAtRequired
+			}
+		},
+	)
+}
+
+// Spanning reads m across the concatenation of readers as if they were a single stream, which is useful for formats that are split across multiple volumes or segments.
+// A field that spans the boundary between two readers is read as contiguous bytes, so m doesn't need to be aware of the boundaries.
+// The io.Reader passed to Read is not used.
+// Writing is passed through to m unchanged, since how output would be split is format specific.
+func Spanning(m Mapper, readers ...io.Reader) Mapper {
+	if m == nil {
+		return nilMapping
+	}
+	return Any(
+		func(_ io.Reader, endian binary.ByteOrder) error {
+			return m.Read(io.MultiReader(readers...), endian)
+		},
+		m.Write,
+	)
+}
+
+// TrailingLenBytes maps a byte slice at the end of a source, which is followed by its length, as is done in some append-optimized formats that are read backward from the end.
+// Reading requires the io.Reader to implement io.ReadSeeker, or io.ReaderAt with a Size method, otherwise ErrSeekRequired is returned.
+// On read, the length is read from the end of the source, and then the preceding length bytes are read into buf.
+// ErrOutOfBounds is returned if the length is larger than the data before it, and the source is left positioned at the end after a successful read.
+// Any bytes between the current position and the start of the data are skipped, so trailing data can be read from the end of a larger source, as is done within AtOffset.
+// On write, length is set to the length of buf, and buf is written followed by the length, which is the reverse of LenBytes.
+func TrailingLenBytes[S SizeType](buf *[]byte, length *S) Mapper {
+	if buf == nil || length == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			rs := randomAccess(r)
+			if rs == nil {
+				return ErrSeekRequired
+			}
+			lenSize := int64(binary.Size(*length))
+			end, err := rs.Seek(0, io.SeekEnd)
+			if err != nil {
+				return err
+			}
+			if end < lenSize {
+				return fmt.Errorf("%w: source is %d bytes, which is too small for the trailing length", ErrOutOfBounds, end)
+			}
+			if _, err := rs.Seek(end-lenSize, io.SeekStart); err != nil {
+				return err
+			}
+			if err := Size(length).Read(rs, endian); err != nil {
+				return err
+			}
+			if uint64(*length) > uint64(end-lenSize) {
+				return fmt.Errorf("%w: trailing length %d exceeds the %d bytes before it", ErrOutOfBounds, *length, end-lenSize)
+			}
+			if _, err := rs.Seek(end-lenSize-int64(*length), io.SeekStart); err != nil {
+				return err
+			}
+			if err := FixedBytes(buf, *length).Read(rs, endian); err != nil {
+				return err
+			}
+			_, err = rs.Seek(end, io.SeekStart)
+			return err
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var err error
+			if *length, err = toSize[S](len(*buf)); err != nil {
+				return err
+			}
+			if _, err := w.Write(*buf); err != nil {
+				return err
+			}
+			return Size(length).Write(w, endian)
+		},
+	)
+}
@@ -0,0 +1,261 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSeekTo(t *testing.T) {
+	var (
+		endian = binary.BigEndian
+		next   uint32
+		val    uint16
+		data   = []byte{
+			0, 0, 0, 8, 0, 1, 0xFF, 0xFF, // Record with 2 bytes of slack
+			0, 0, 0, 14, 0, 2, // Record without slack
+		}
+	)
+	var offset int64
+	r := bytes.NewReader(data)
+	m := SeekTo(r, &offset, ValidateRead(MapSequence(Int(&next), Int(&val)), func(err error) error {
+		offset = int64(next)
+		return err
+	}))
+
+	assert.NoError(t, m.Read(r, endian))
+	assert.Equal(t, uint16(1), val)
+	assert.Equal(t, int64(8), offset)
+	assert.NoError(t, m.Read(r, endian))
+	assert.Equal(t, uint16(2), val)
+	assert.Equal(t, int64(14), offset)
+	assert.Equal(t, 0, r.Len())
+}
+
+func TestResumable(t *testing.T) {
+	var (
+		endian = binary.BigEndian
+		offset int64
+		a, b   uint16
+		data   = []byte{0, 1, 0, 2, 0, 3, 0}
+	)
+	r := bytes.NewReader(data)
+	m := Resumable(r, &offset, MapSequence(Int(&a), Int(&b)))
+	assert.NoError(t, m.Read(r, endian))
+	assert.Equal(t, int64(4), offset)
+	assert.Equal(t, uint16(1), a)
+	assert.Equal(t, uint16(2), b)
+
+	// Simulate resuming with a new reader from a saved offset.
+	r = bytes.NewReader(data)
+	m = Resumable(r, &offset, MapSequence(Int(&a), Int(&b)))
+	err := m.Read(r, endian)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	var offErr *OffsetError
+	assert.ErrorAs(t, err, &offErr)
+	assert.Equal(t, int64(7), offErr.Offset)
+	assert.Equal(t, int64(4), offset, "The offset should not advance on error")
+	assert.Equal(t, uint16(3), a)
+}
+
+// memWriteSeeker is an in-memory io.WriteSeeker for testing.
+type memWriteSeeker struct {
+	buf []byte
+	pos int64
+}
+
+func (m *memWriteSeeker) Write(p []byte) (int, error) {
+	if end := m.pos + int64(len(p)); end > int64(len(m.buf)) {
+		m.buf = append(m.buf, make([]byte, end-int64(len(m.buf)))...)
+	}
+	n := copy(m.buf[m.pos:], p)
+	m.pos += int64(n)
+	return n, nil
+}
+
+func (m *memWriteSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += m.pos
+	case io.SeekEnd:
+		offset += int64(len(m.buf))
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	m.pos = offset
+	return offset, nil
+}
+
+func TestOffsetDeltaBytes(t *testing.T) {
+	var (
+		endian   = binary.BigEndian
+		offsets  [3]uint32
+		sections = [][]byte{[]byte("first"), []byte("second!")}
+	)
+	table := MapSequence(Int(&offsets[0]), Int(&offsets[1]), Int(&offsets[2]))
+	body := MapSequence(
+		OffsetDeltaBytes(&sections[0], &offsets[0], &offsets[1]),
+		OffsetDeltaBytes(&sections[1], &offsets[1], &offsets[2]),
+	)
+
+	ws := new(memWriteSeeker)
+	_, _ = ws.Seek(12, io.SeekStart)
+	assert.NoError(t, body.Write(ws, endian))
+	assert.Equal(t, [3]uint32{12, 17, 24}, offsets)
+	_, _ = ws.Seek(0, io.SeekStart)
+	assert.NoError(t, table.Write(ws, endian))
+
+	offsets, sections[0], sections[1] = [3]uint32{}, nil, nil
+	r := bytes.NewReader(ws.buf)
+	assert.NoError(t, MapSequence(table, body).Read(r, endian))
+	assert.Equal(t, "first", string(sections[0]))
+	assert.Equal(t, "second!", string(sections[1]))
+
+	var (
+		buf        bytes.Buffer
+		data       []byte
+		start, end = uint32(5), uint32(4)
+	)
+	assert.ErrorIs(t, OffsetDeltaBytes(&data, &start, &end).Read(&buf, endian), ErrOffsetOrder)
+	data = []byte{1, 2}
+	assert.NoError(t, OffsetDeltaBytes(&data, &start, &end).Write(&buf, endian))
+	assert.Equal(t, uint32(7), end, "The start offset is used as-is without an io.Seeker")
+}
+
+func TestAtOffset(t *testing.T) {
+	var (
+		endian  = binary.BigEndian
+		data    = []byte{0, 0, 0, 8, 0, 0, 0, 12, 'a', 'b', 'c', 0, 0, 0x2A}
+		offsets [2]int64
+		name    string
+		val     uint16
+	)
+	src := bytes.NewReader(data)
+	var rawOffsets [2]uint32
+	m := MapSequence(
+		Int(&rawOffsets[0]),
+		Int(&rawOffsets[1]),
+		Any(func(io.Reader, binary.ByteOrder) error {
+			offsets[0], offsets[1] = int64(rawOffsets[0]), int64(rawOffsets[1])
+			return nil
+		}, nil),
+		AtOffset(src, &offsets[1], Int(&val)),
+		AtOffset(src, &offsets[0], NullTermString(&name)),
+	)
+	assert.NoError(t, m.Read(src, endian))
+	assert.Equal(t, "abc", name)
+	assert.Equal(t, uint16(0x2A), val)
+	pos, _ := src.Seek(0, io.SeekCurrent)
+	assert.Equal(t, int64(8), pos, "The stream position should be unaffected by AtOffset")
+
+	ws := new(memWriteSeeker)
+	_, _ = ws.Write(make([]byte, 4))
+	assert.NoError(t, AtOffset(src, &offsets[1], Int(&val)).Write(ws, endian))
+	assert.Equal(t, int64(4), ws.pos, "The writer should be returned to its original position")
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x2A}, ws.buf)
+
+	var buf bytes.Buffer
+	assert.ErrorIs(t, AtOffset(src, &offsets[1], Int(&val)).Write(&buf, endian), ErrWriterAtRequired)
+}
+
+// readerAtOnly is a random access source that can't seek, like a memory-mapped file wrapper.
+type readerAtOnly struct {
+	data []byte
+}
+
+func (r *readerAtOnly) Read([]byte) (int, error) {
+	return 0, errors.New("sequential reads should not be used")
+}
+
+func (r *readerAtOnly) ReadAt(p []byte, off int64) (int, error) {
+	return bytes.NewReader(r.data).ReadAt(p, off)
+}
+
+func (r *readerAtOnly) Size() int64 {
+	return int64(len(r.data))
+}
+
+func TestAtOffset_Sized(t *testing.T) {
+	var (
+		endian  = binary.BigEndian
+		data    = []byte{0xFF, 'a', 'b', 'c', 0, 3}
+		offset  = int64(1)
+		trailer []byte
+		length  uint8
+	)
+	src := &readerAtOnly{data: data}
+	assert.NoError(t, AtOffset(src, &offset, TrailingLenBytes(&trailer, &length)).Read(src, endian), "The section should end at the size of the source")
+	assert.Equal(t, []byte{'b', 'c', 0}, trailer)
+
+	trailer = nil
+	assert.NoError(t, TrailingLenBytes(&trailer, &length).Read(src, endian), "A sized io.ReaderAt should be usable without io.Seeker")
+	assert.Equal(t, []byte{'b', 'c', 0}, trailer)
+
+	offset = 7
+	assert.ErrorIs(t, AtOffset(src, &offset, Byte(new(byte))).Read(src, endian), ErrOutOfBounds)
+}
+
+func TestSpanning(t *testing.T) {
+	var (
+		endian = binary.BigEndian
+		a      uint32
+		name   string
+		b      uint64
+	)
+	m := Spanning(MapSequence(Int(&a), NullTermString(&name), Int(&b)),
+		bytes.NewReader([]byte{0, 0}),
+		iotest.OneByteReader(bytes.NewReader([]byte{1, 2, 'a', 'b'})),
+		bytes.NewReader([]byte{'c', 0, 0, 0, 0}),
+		bytes.NewReader(nil),
+		bytes.NewReader([]byte{0, 0, 0, 0, 9}),
+	)
+	assert.NoError(t, m.Read(nil, endian))
+	assert.Equal(t, uint32(0x0102), a)
+	assert.Equal(t, "abc", name)
+	assert.Equal(t, uint64(9), b)
+
+	m = Spanning(Int(&a), bytes.NewReader([]byte{0, 0}), bytes.NewReader([]byte{1}))
+	assert.ErrorIs(t, m.Read(nil, endian), io.ErrUnexpectedEOF)
+
+	var buf bytes.Buffer
+	a = 5
+	assert.NoError(t, Spanning(Int(&a)).Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0, 5}, buf.Bytes())
+}
+
+func TestTrailingLenBytes(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		header = uint16(0xABCD)
+		data   = []byte("appended")
+		length uint16
+	)
+	m := MapSequence(Int(&header), TrailingLenBytes(&data, &length))
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint16(8), length)
+	assert.Equal(t, "\xAB\xCDappended\x00\x08", buf.String())
+
+	header, data, length = 0, nil, 0
+	r := bytes.NewReader(buf.Bytes())
+	assert.NoError(t, m.Read(r, endian))
+	assert.Equal(t, uint16(0xABCD), header)
+	assert.Equal(t, "appended", string(data))
+	assert.Equal(t, 0, r.Len(), "Source should be positioned at the end")
+
+	assert.ErrorIs(t, TrailingLenBytes(&data, &length).Read(&buf, endian), ErrSeekRequired)
+
+	data = nil
+	r = bytes.NewReader(buf.Bytes())
+	assert.NoError(t, TrailingLenBytes(&data, &length).Read(r, endian), "Unread bytes before the data should be skipped")
+	assert.Equal(t, "appended", string(data))
+	assert.Equal(t, 0, r.Len(), "Source should be positioned at the end")
+
+	assert.ErrorIs(t, TrailingLenBytes(&data, &length).Read(bytes.NewReader([]byte{1, 0, 9}), endian), ErrOutOfBounds)
+	assert.ErrorIs(t, TrailingLenBytes(&data, &length).Read(bytes.NewReader([]byte{9}), endian), ErrOutOfBounds)
+}
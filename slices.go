@@ -1,14 +1,46 @@
 package bin
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+var (
+	ErrSizeOverflow  = errors.New("size is too large for the size type")
+	ErrMatrixShape   = errors.New("matrix does not match the specified dimensions")
+	ErrClosedChan    = errors.New("channel closed before all elements were received")
+	ErrOutOfBounds   = errors.New("index out of bounds")
+	ErrLenUnderflow  = errors.New("stored length is less than the length overhead")
+	ErrCountMismatch = errors.New("slice length does not match the expected count")
+	ErrChanFull      = errors.New("channel buffer is full")
+	ErrNotSorted     = errors.New("elements are not sorted")
+	ErrNotFixedSize  = errors.New("type is not a fixed size")
+	ErrCountRange    = errors.New("count is outside the allowed range")
+	ErrDuplicateElem = errors.New("duplicate element in set")
+	ErrChanReused    = errors.New("channel was already closed by a previous read")
+	ErrInvalidLength = errors.New("invalid length")
 )
 
 type SizeType interface {
 	uint8 | uint16 | uint32 | uint64
 }
 
+// toSize converts n to the size type S, returning ErrSizeOverflow if n cannot be represented by S.
+func toSize[S SizeType](n int) (S, error) {
+	size := S(n)
+	if n < 0 || uint64(size) != uint64(n) {
+		return 0, fmt.Errorf("%w: %d", ErrSizeOverflow, n)
+	}
+	return size, nil
+}
+
 // Size maps any value that can reasonably be used to express a size.
 func Size[S SizeType](size *S) Mapper {
 	if size == nil {
@@ -47,6 +79,42 @@ func FixedBytes[S SizeType](buf *[]byte, length S) Mapper {
 	}
 }
 
+// FixedCodec maps a byte slice of a known length that needs a bespoke byte-level transform, such as a proprietary scrambling.
+// On read, length bytes are read, and then transformed in place with decode before being set in buf.
+// On write, buf is copied and truncated or zero-padded to length bytes like FixedBytes, and the copy is transformed in place with encode before being written.
+// The transform functions always get exactly length bytes, and buf is never modified on write.
+// ErrInvalidLength is returned if length is negative.
+func FixedCodec(buf *[]byte, length int, decode func([]byte) error, encode func([]byte) error) Mapper {
+	if buf == nil || decode == nil || encode == nil {
+		return nilMapping
+	}
+	if length < 0 {
+		return errMapping(fmt.Errorf("%w: fixed codec length %d", ErrInvalidLength, length))
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var raw []byte
+			if err := FixedBytes(&raw, uint64(length)).Read(r, endian); err != nil {
+				return err
+			}
+			if err := decode(raw); err != nil {
+				return err
+			}
+			*buf = raw
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			out := make([]byte, length)
+			copy(out, *buf)
+			if err := encode(out); err != nil {
+				return err
+			}
+			_, err := w.Write(out)
+			return err
+		},
+	}
+}
+
 // LenBytes is used for situations where an arbitrarily sized byte slice is encoded after its length.
 // This mapper will read the length, and then length number of bytes into a byte slice.
 // The mapper will write the length and bytes in the same order.
@@ -73,6 +141,40 @@ func LenBytes[S SizeType](buf *[]byte, length *S) Mapper {
 	}
 }
 
+// OverheadLenBytes is like LenBytes, but for formats where the stored length also counts a fixed overhead, such as a header that precedes the payload or the length field itself.
+// On read, overhead is subtracted from the stored length to get the payload size, and ErrLenUnderflow is returned if the stored length is less than overhead.
+// On write, length is set to the size of buf plus overhead before it's written.
+func OverheadLenBytes[S SizeType](buf *[]byte, length *S, overhead S) Mapper {
+	if buf == nil || length == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Size(length).Read(r, endian); err != nil {
+				return err
+			}
+			if *length < overhead {
+				return fmt.Errorf("%w: stored length %d, overhead %d", ErrLenUnderflow, *length, overhead)
+			}
+			return FixedBytes(buf, *length-overhead).Read(r, endian)
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			payloadLen, err := toSize[S](len(*buf))
+			if err != nil {
+				return err
+			}
+			if payloadLen > ^S(0)-overhead {
+				return fmt.Errorf("%w: %d + %d", ErrSizeOverflow, payloadLen, overhead)
+			}
+			*length = payloadLen + overhead
+			if err := Size(length).Write(w, endian); err != nil {
+				return err
+			}
+			return FixedBytes(buf, payloadLen).Write(w, endian)
+		},
+	}
+}
+
 // Slice will produce a mapper informed from the given function to use a slice of values.
 // The slice length must be known ahead of time.
 // The mapVal function will be used to create a Mapper that relates to the type returned from allocNext.
@@ -135,6 +237,188 @@ func LenSlice[E any, S SizeType](target *[]E, count *S, mapVal func(*E) Mapper)
 	}
 }
 
+// BoundedSlice is the same as LenSlice, except that the count must be within [min, max].
+// On read, ErrCountRange is returned before any elements are allocated if the count is out of range, which protects against huge counts in untrusted input.
+// On write, count is set to the length of target, and ErrCountRange is returned if it's out of range.
+// ErrCountRange is also returned if min is greater than max.
+func BoundedSlice[E any, S SizeType](target *[]E, count *S, min, max S, mapVal func(*E) Mapper) Mapper {
+	if target == nil || count == nil {
+		return nilMapping
+	}
+	if min > max {
+		return errMapping(fmt.Errorf("%w: minimum count %d is greater than maximum count %d", ErrCountRange, min, max))
+	}
+	checkRange := func(n uint64) error {
+		if n < uint64(min) || n > uint64(max) {
+			return fmt.Errorf("%w: count %d is not in [%d, %d]", ErrCountRange, n, min, max)
+		}
+		return nil
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Size(count).Read(r, endian); err != nil {
+				return err
+			}
+			if err := checkRange(uint64(*count)); err != nil {
+				return err
+			}
+			return Slice(target, *count, mapVal).Read(r, endian)
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if err := checkRange(uint64(len(*target))); err != nil {
+				return err
+			}
+			*count = S(len(*target))
+			return LenSlice(target, count, mapVal).Write(w, endian)
+		},
+	}
+}
+
+// FixedStructSlice is an optimized alternative to Slice for elements that only contain fixed-size fields, such as a struct of integers and floats.
+// The whole slice is read or written with a single binary.Read or binary.Write call, rather than mapping each element individually.
+// Fields are encoded in declaration order without padding, as binary.Read and binary.Write do for structs.
+// ErrNotFixedSize is returned if E contains fields that aren't fixed-size, such as strings or slices, or if any struct field is unexported, since binary.Read can't set them.
+// Like Slice, target is truncated or zero-padded to count elements on write.
+func FixedStructSlice[E any, S SizeType](target *[]E, count S) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	var zero E
+	if binary.Size(zero) < 0 {
+		return errMapping(fmt.Errorf("%w: %T", ErrNotFixedSize, zero))
+	}
+	if err := checkExportedFields(reflect.TypeOf(zero)); err != nil {
+		return errMapping(err)
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			input := make([]E, count)
+			if err := binary.Read(r, endian, input); err != nil {
+				return err
+			}
+			*target = input
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			output := make([]E, count)
+			copy(output, *target)
+			return binary.Write(w, endian, output)
+		},
+	}
+}
+
+// checkExportedFields returns ErrNotFixedSize if t is, or contains, a struct with an unexported field other than the blank fields that encoding/binary skips.
+func checkExportedFields(t reflect.Type) error {
+	switch t.Kind() {
+	case reflect.Array:
+		return checkExportedFields(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Name != "_" && !f.IsExported() {
+				return fmt.Errorf("%w: field %s of %s is unexported", ErrNotFixedSize, f.Name, t)
+			}
+			if err := checkExportedFields(f.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkSorted returns ErrNotSorted with the first index i where less(elems[i-1], elems[i]) is false.
+func checkSorted[E any](elems []E, less func(a, b E) bool) error {
+	for i := 1; i < len(elems); i++ {
+		if !less(elems[i-1], elems[i]) {
+			return fmt.Errorf("%w: element at index %d is not ordered after index %d", ErrNotSorted, i, i-1)
+		}
+	}
+	return nil
+}
+
+// AssertSorted maps a count-prefixed slice like LenSlice, and enforces that elements are strictly increasing according to less.
+// This is useful for sorted indexes that are expected to support binary search.
+// ErrNotSorted is returned with the first offending index if consecutive elements are out of order or equal.
+// The order is checked after reading, and before writing so an invalid index is never written.
+func AssertSorted[E any, S SizeType](target *[]E, count *S, less func(a, b E) bool, mapVal func(*E) Mapper) Mapper {
+	if target == nil || count == nil || less == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var elems []E
+			if err := LenSlice(&elems, count, mapVal).Read(r, endian); err != nil {
+				return err
+			}
+			if err := checkSorted(elems, less); err != nil {
+				return err
+			}
+			*target = elems
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if err := checkSorted(*target, less); err != nil {
+				return err
+			}
+			return LenSlice(target, count, mapVal).Write(w, endian)
+		},
+	}
+}
+
+// SortedSet maps a count-prefixed slice like LenSlice, where the elements have set semantics and are stored in ascending order without duplicates.
+// On write, a sorted and deduplicated copy of target is written, so target is left unchanged, and count is set to the number of unique elements.
+// On read, ErrNotSorted is returned if the elements are out of order, and ErrDuplicateElem is returned if an element is repeated, since either means the set is corrupt.
+// ErrNotSorted is also returned on write if target contains a floating point NaN, since it can't be ordered.
+func SortedSet[E AnyOrdered, S SizeType](target *[]E, count *S, mapVal func(*E) Mapper) Mapper {
+	if target == nil || count == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var elems []E
+			if err := LenSlice(&elems, count, mapVal).Read(r, endian); err != nil {
+				return err
+			}
+			for i := 1; i < len(elems); i++ {
+				switch {
+				case elems[i-1] == elems[i]:
+					return fmt.Errorf("%w: element at index %d is equal to index %d", ErrDuplicateElem, i, i-1)
+				case !(elems[i-1] < elems[i]):
+					return fmt.Errorf("%w: element at index %d is not ordered after index %d", ErrNotSorted, i, i-1)
+				}
+			}
+			*target = elems
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			for i, e := range *target {
+				// Only NaN is not equal to itself.
+				if e != e {
+					return fmt.Errorf("%w: element at index %d is NaN", ErrNotSorted, i)
+				}
+			}
+			sorted := make([]E, len(*target))
+			copy(sorted, *target)
+			sort.Slice(sorted, func(i, j int) bool {
+				return sorted[i] < sorted[j]
+			})
+			var elems []E
+			for i, e := range sorted {
+				if i > 0 && e == sorted[i-1] {
+					continue
+				}
+				elems = append(elems, e)
+			}
+			size, err := toSize[S](len(elems))
+			if err != nil {
+				return err
+			}
+			*count = size
+			return LenSlice(&elems, count, mapVal).Write(w, endian)
+		},
+	}
+}
+
 // DynamicSlice tries to accomplish a happy medium between LenSlice and Slice.
 // A uint32 will be used to store the size of the given slice, but it's not necessary to read this from a field, rather it will be discovered at write time.
 // This means that the size will be available at read time by first reading the uint32 with LenSlice, without requiring a caller provided field.
@@ -154,3 +438,479 @@ func DynamicSlice[E any](target *[]E, mapVal func(*E) Mapper) Mapper {
 		},
 	}
 }
+
+// Matrix maps a 2D slice with its row and column counts prepended.
+// Elements are read and written in row-major order.
+// On write, an error will be returned if the target doesn't have exactly rows rows, or if any row doesn't have exactly cols columns.
+func Matrix[E any, S SizeType](target *[][]E, rows, cols *S, mapVal func(*E) Mapper) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	if rows == nil || cols == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if err := MapSequence(Size(rows), Size(cols)).Read(r, endian); err != nil {
+				return err
+			}
+			input := make([][]E, *rows)
+			for i := range input {
+				if err := Slice(&input[i], *cols, mapVal).Read(r, endian); err != nil {
+					return err
+				}
+			}
+			*target = input
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if uint64(len(*target)) != uint64(*rows) {
+				return fmt.Errorf("%w: expected %d rows, but found %d", ErrMatrixShape, *rows, len(*target))
+			}
+			for i, row := range *target {
+				if uint64(len(row)) != uint64(*cols) {
+					return fmt.Errorf("%w: expected %d columns in row %d, but found %d", ErrMatrixShape, *cols, i, len(row))
+				}
+			}
+			if err := MapSequence(Size(rows), Size(cols)).Write(w, endian); err != nil {
+				return err
+			}
+			for i := range *target {
+				if err := Slice(&(*target)[i], *cols, mapVal).Write(w, endian); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// FixedMatrix maps a matrix of float32 values with dimensions that are known ahead of time, such as a 4x4 transformation matrix, in row-major order with no dimension prefix.
+// On read, a new rows x cols matrix is allocated and read into target.
+// On write, ErrMatrixShape is returned if target doesn't have exactly rows rows of cols columns.
+func FixedMatrix(target *[][]float32, rows, cols int) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	if rows < 0 || cols < 0 {
+		return errMapping(fmt.Errorf("%w: invalid dimensions %dx%d", ErrMatrixShape, rows, cols))
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			// A single backing slice keeps the rows contiguous.
+			values := make([]float32, rows*cols)
+			if err := binary.Read(r, endian, values); err != nil {
+				return err
+			}
+			input := make([][]float32, rows)
+			for i := range input {
+				input[i] = values[i*cols : (i+1)*cols : (i+1)*cols]
+			}
+			*target = input
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if len(*target) != rows {
+				return fmt.Errorf("%w: expected %d rows, but found %d", ErrMatrixShape, rows, len(*target))
+			}
+			values := make([]float32, 0, rows*cols)
+			for i, row := range *target {
+				if len(row) != cols {
+					return fmt.Errorf("%w: expected %d columns in row %d, but found %d", ErrMatrixShape, cols, i, len(row))
+				}
+				values = append(values, row...)
+			}
+			return binary.Write(w, endian, values)
+		},
+	}
+}
+
+// StreamSlice maps a count-prefixed sequence of elements without materializing them as a slice.
+// On read, each element is passed to onElem as soon as it's decoded, and any error returned from onElem will stop the read.
+// On write, the count will be written, and then nextElem will be called count times to produce each element to write.
+// Either callback may be nil if the Mapper is only used in one direction, in which case ErrNilReadWrite will be returned for the other direction.
+func StreamSlice[E any, S SizeType](count *S, mapVal func(*E) Mapper, onElem func(E) error, nextElem func() (E, error)) Mapper {
+	if count == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if onElem == nil {
+				return ErrNilReadWrite
+			}
+			if err := Size(count).Read(r, endian); err != nil {
+				return err
+			}
+			for i := S(0); i < *count; i++ {
+				var e E
+				if err := mapVal(&e).Read(r, endian); err != nil {
+					return err
+				}
+				if err := onElem(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if nextElem == nil {
+				return ErrNilReadWrite
+			}
+			if err := Size(count).Write(w, endian); err != nil {
+				return err
+			}
+			for i := S(0); i < *count; i++ {
+				e, err := nextElem()
+				if err != nil {
+					return err
+				}
+				if err := mapVal(&e).Write(w, endian); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// VarintDelimitedStream maps a stream of elements, each prefixed with its size in bytes as an unsigned varint.
+// This is the format commonly used for streams of length-delimited Protocol Buffers messages.
+// On read, elements will be read until EOF is reached, and each element is bounded to its declared size.
+// Any bytes within an element that are not read by mapVal will be skipped, and io.ErrUnexpectedEOF is returned if the stream ends within an element.
+// Since the stream is read until EOF, this should generally be the last Mapper used for a source.
+func VarintDelimitedStream[E any](target *[]E, mapVal func(*E) Mapper) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				input []E
+				ubr   = &unbufferedByteReader{reader: r}
+			)
+			for {
+				length, err := binary.ReadUvarint(ubr)
+				if err != nil {
+					if errors.Is(err, io.EOF) {
+						break
+					}
+					return err
+				}
+				lr, err := limitTo(r, length)
+				if err != nil {
+					return err
+				}
+				var e E
+				if err := mapVal(&e).Read(lr, endian); err != nil {
+					// Only a clean end between elements is EOF, so a short payload after the length is unexpected.
+					if errors.Is(err, io.EOF) {
+						return io.ErrUnexpectedEOF
+					}
+					return err
+				}
+				if _, err := io.Copy(io.Discard, lr); err != nil {
+					return err
+				}
+				if lr.N != 0 {
+					return io.ErrUnexpectedEOF
+				}
+				input = append(input, e)
+			}
+			*target = input
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			var (
+				buf    bytes.Buffer
+				prefix = make([]byte, binary.MaxVarintLen64)
+			)
+			for i := range *target {
+				buf.Reset()
+				if err := mapVal(&(*target)[i]).Write(&buf, endian); err != nil {
+					return err
+				}
+				n := binary.PutUvarint(prefix, uint64(buf.Len()))
+				if _, err := w.Write(prefix[:n]); err != nil {
+					return err
+				}
+				if _, err := buf.WriteTo(w); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// ChannelSlice maps a count-prefixed sequence of elements through channels, so elements may be processed concurrently with decoding.
+// On read, each decoded element is sent on out, and out is closed once reading stops, whether successful or not.
+// Since out is closed, the Mapper can only be read once, and ErrChanReused is returned for any later read.
+// On write, the count is written, and then count elements are received from in and written.
+// ErrClosedChan is returned if in is closed before count elements are received.
+// Either channel may be nil if the Mapper is only used in one direction, in which case ErrNilReadWrite will be returned for the other direction.
+// Cancelling ctx will stop a read or write that is waiting on a channel, and return the context's error.
+func ChannelSlice[E any, S SizeType](ctx context.Context, count *S, mapVal func(*E) Mapper, out chan<- E, in <-chan E) Mapper {
+	if count == nil {
+		return nilMapping
+	}
+	var closed atomic.Bool
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if out == nil {
+				return ErrNilReadWrite
+			}
+			if !closed.CompareAndSwap(false, true) {
+				return ErrChanReused
+			}
+			defer close(out)
+			if err := Size(count).Read(r, endian); err != nil {
+				return err
+			}
+			for i := S(0); i < *count; i++ {
+				var e E
+				if err := mapVal(&e).Read(r, endian); err != nil {
+					return err
+				}
+				select {
+				case out <- e:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if in == nil {
+				return ErrNilReadWrite
+			}
+			if err := Size(count).Write(w, endian); err != nil {
+				return err
+			}
+			for i := S(0); i < *count; i++ {
+				var (
+					e  E
+					ok bool
+				)
+				select {
+				case e, ok = <-in:
+					if !ok {
+						return fmt.Errorf("%w: received %d of %d elements", ErrClosedChan, i, *count)
+					}
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				if err := mapVal(&e).Write(w, endian); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// DrainChannel maps the elements currently buffered in ch as a slice prefixed with its uint32 length, which is useful for snapshotting the state of a buffered channel.
+// On write, ch is drained without blocking, so receiving stops as soon as the channel is empty or closed, and elements sent after that point are not included.
+// The drained elements are removed from ch, and they're lost if writing them fails.
+// On read, each decoded element is sent on ch without blocking, so ch must have enough free buffer space for all elements, otherwise ErrChanFull is returned.
+// The free space is checked before anything is sent, so no elements are delivered if there isn't room for all of them, unless another goroutine is sending on ch at the same time.
+// The channel is never closed by this Mapper.
+func DrainChannel[E any](ch chan E, mapVal func(*E) Mapper) Mapper {
+	if ch == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var elems []E
+			if err := DynamicSlice(&elems, mapVal).Read(r, endian); err != nil {
+				return err
+			}
+			if free := cap(ch) - len(ch); len(elems) > free {
+				return fmt.Errorf("%w: %d elements, but only %d free", ErrChanFull, len(elems), free)
+			}
+			for i, e := range elems {
+				select {
+				case ch <- e:
+				default:
+					return fmt.Errorf("%w: sent %d of %d elements", ErrChanFull, i, len(elems))
+				}
+			}
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			var elems []E
+		drain:
+			for {
+				select {
+				case e, ok := <-ch:
+					if !ok {
+						break drain
+					}
+					elems = append(elems, e)
+				default:
+					break drain
+				}
+			}
+			return DynamicSlice(&elems, mapVal).Write(w, endian)
+		},
+	}
+}
+
+// ByteLenSlice maps a slice of fixed-size elements prefixed with the total size of the elements in bytes, rather than an element count.
+// Each element must be exactly elemSize bytes, otherwise ErrSizeMismatch is returned.
+// On read, ErrSizeMismatch is also returned if the byte length is not evenly divisible by elemSize.
+// On write, byteLen is set to the total size of the elements.
+// ErrInvalidWidth is returned if elemSize is not positive.
+func ByteLenSlice[E any, S SizeType](target *[]E, byteLen *S, elemSize int, mapVal func(*E) Mapper) Mapper {
+	if target == nil || byteLen == nil {
+		return nilMapping
+	}
+	if elemSize <= 0 {
+		return errMapping(fmt.Errorf("%w: element size %d", ErrInvalidWidth, elemSize))
+	}
+	exactVal := func(e *E) Mapper {
+		return ExactSize(mapVal(e), int64(elemSize))
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Size(byteLen).Read(r, endian); err != nil {
+				return err
+			}
+			if uint64(*byteLen)%uint64(elemSize) != 0 {
+				return fmt.Errorf("%w: byte length %d is not a multiple of element size %d", ErrSizeMismatch, *byteLen, elemSize)
+			}
+			return Slice(target, uint64(*byteLen)/uint64(elemSize), exactVal).Read(r, endian)
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			length, err := toSize[S](len(*target) * elemSize)
+			if err != nil {
+				return err
+			}
+			*byteLen = length
+			if err := Size(byteLen).Write(w, endian); err != nil {
+				return err
+			}
+			return Slice(target, uint64(len(*target)), exactVal).Write(w, endian)
+		},
+	}
+}
+
+// SliceUntilBytes maps a slice of variable-size elements prefixed with the total size of the elements in bytes, as is the case with sections that contain records until the section length is exhausted.
+// On read, elements are read from a reader bounded to sectionLen bytes until the bound is exhausted.
+// An element that would extend past the end of the section results in io.ErrUnexpectedEOF, and ErrSizeMismatch is returned if an element consumes no bytes.
+// On write, the elements are buffered so sectionLen can be set to their total size before they're written.
+func SliceUntilBytes[E any, S SizeType](target *[]E, sectionLen *S, mapVal func(*E) Mapper) Mapper {
+	if target == nil || sectionLen == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Size(sectionLen).Read(r, endian); err != nil {
+				return err
+			}
+			lr, err := limitTo(r, uint64(*sectionLen))
+			if err != nil {
+				return err
+			}
+			var input []E
+			for lr.N > 0 {
+				var (
+					e         E
+					remaining = lr.N
+				)
+				if err := mapVal(&e).Read(lr, endian); err != nil {
+					if errors.Is(err, io.EOF) {
+						return io.ErrUnexpectedEOF
+					}
+					return err
+				}
+				if lr.N == remaining {
+					return fmt.Errorf("%w: element at index %d consumed no bytes", ErrSizeMismatch, len(input))
+				}
+				input = append(input, e)
+			}
+			*target = input
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			for i := range *target {
+				if err := mapVal(&(*target)[i]).Write(&buf, endian); err != nil {
+					return err
+				}
+			}
+			length, err := toSize[S](buf.Len())
+			if err != nil {
+				return err
+			}
+			*sectionLen = length
+			if err := Size(sectionLen).Write(w, endian); err != nil {
+				return err
+			}
+			_, err = buf.WriteTo(w)
+			return err
+		},
+	}
+}
+
+// SparseSlice maps a slice where most elements are expected to be the zero value.
+// The total length of the slice is written first, followed by the count of non-zero elements, and then the index and value of each non-zero element.
+// On read, a slice of the total length is allocated, and the given indexes are populated.
+// ErrOutOfBounds is returned on read if an index is not within the total length.
+// On write, totalLen is set to the length of the slice.
+func SparseSlice[E any, S SizeType](target *[]E, totalLen *S, mapVal func(*E) Mapper) Mapper {
+	if target == nil || totalLen == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var count S
+			if err := MapSequence(Size(totalLen), Size(&count)).Read(r, endian); err != nil {
+				return err
+			}
+			if count > *totalLen {
+				return fmt.Errorf("%w: %d populated entries in a slice of length %d", ErrOutOfBounds, count, *totalLen)
+			}
+			input := make([]E, *totalLen)
+			for i := S(0); i < count; i++ {
+				var idx S
+				if err := Size(&idx).Read(r, endian); err != nil {
+					return err
+				}
+				if idx >= *totalLen {
+					return fmt.Errorf("%w: index %d in a slice of length %d", ErrOutOfBounds, idx, *totalLen)
+				}
+				if err := mapVal(&input[idx]).Read(r, endian); err != nil {
+					return err
+				}
+			}
+			*target = input
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			length, err := toSize[S](len(*target))
+			if err != nil {
+				return err
+			}
+			var populated []S
+			for i := range *target {
+				if !reflect.ValueOf(&(*target)[i]).Elem().IsZero() {
+					populated = append(populated, S(i))
+				}
+			}
+			count := S(len(populated))
+			*totalLen = length
+			if err := MapSequence(Size(totalLen), Size(&count)).Write(w, endian); err != nil {
+				return err
+			}
+			for _, idx := range populated {
+				if err := Size(&idx).Write(w, endian); err != nil {
+					return err
+				}
+				if err := mapVal(&(*target)[idx]).Write(w, endian); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
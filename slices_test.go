@@ -2,8 +2,12 @@ package bin
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"math"
 	"testing"
 )
 
@@ -31,6 +35,65 @@ func TestLenBytes(t *testing.T) {
 	assert.Equal(t, "Hello!", string(test.data))
 }
 
+func TestFixedCodec(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		data   = []byte("secret")
+		key    = byte(0x5A)
+	)
+	scramble := func(b []byte) error {
+		for i := range b {
+			b[i] ^= key + byte(i)
+		}
+		return nil
+	}
+	m := FixedCodec(&data, 8, scramble, scramble)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte("secret"), data, "Buffer should not be modified on write")
+	assert.Len(t, buf.Bytes(), 8)
+	assert.Equal(t, byte('s')^0x5A, buf.Bytes()[0])
+	assert.Equal(t, byte(0x5A+7), buf.Bytes()[7])
+
+	data = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []byte("secret\x00\x00"), data)
+
+	errDecode := errors.New("bad field")
+	m = FixedCodec(&data, 2, func([]byte) error { return errDecode }, scramble)
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{1, 2}), endian), errDecode)
+
+	assert.ErrorIs(t, FixedCodec(&data, -1, scramble, scramble).Read(&buf, endian), ErrInvalidLength)
+}
+
+func TestOverheadLenBytes(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		data   = []byte("Hello!")
+		length uint16
+	)
+	m := OverheadLenBytes(&data, &length, 2)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint16(8), length)
+	assert.Equal(t, []byte{0, 8, 'H', 'e', 'l', 'l', 'o', '!'}, buf.Bytes())
+
+	data, length = nil, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(8), length)
+	assert.Equal(t, "Hello!", string(data))
+
+	buf.Reset()
+	buf.Write([]byte{0, 1})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrLenUnderflow)
+
+	var (
+		small    uint8
+		tooLarge = make([]byte, 255)
+	)
+	assert.ErrorIs(t, OverheadLenBytes(&tooLarge, &small, 1).Write(&buf, endian), ErrSizeOverflow)
+}
+
 func TestLenSlice(t *testing.T) {
 	test := struct {
 		len  uint8
@@ -53,6 +116,118 @@ func TestLenSlice(t *testing.T) {
 	assert.Equal(t, []byte("Hello!"), test.data)
 }
 
+func TestBoundedSlice(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		vals   = []uint16{1, 2}
+		count  uint8
+	)
+	m := BoundedSlice(&vals, &count, 1, 3, Int[uint16])
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint8(2), count)
+	assert.Equal(t, []byte{2, 0, 1, 0, 2}, buf.Bytes())
+
+	vals, count = nil, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []uint16{1, 2}, vals)
+
+	err := m.Read(bytes.NewReader([]byte{0xFF}), endian)
+	assert.ErrorIs(t, err, ErrCountRange)
+	assert.ErrorContains(t, err, "count 255 is not in [1, 3]")
+	assert.ErrorIs(t, m.Read(bytes.NewReader([]byte{0}), endian), ErrCountRange)
+
+	vals = nil
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrCountRange)
+	vals = make([]uint16, 4)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrCountRange)
+
+	vals = []uint16{1}
+	assert.ErrorIs(t, BoundedSlice(&vals, &count, 3, 1, Int[uint16]).Write(&buf, endian), ErrCountRange)
+}
+
+type fixedVertex struct {
+	X, Y, Z float32
+	Color   uint32
+	Flags   [2]uint8
+}
+
+func mapFixedVertex(v *fixedVertex) Mapper {
+	return MapSequence(Float(&v.X), Float(&v.Y), Float(&v.Z), Int(&v.Color), Int(&v.Flags[0]), Int(&v.Flags[1]))
+}
+
+func TestFixedStructSlice(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		expected bytes.Buffer
+		endian   = binary.LittleEndian
+		vertices = []fixedVertex{
+			{X: 1, Y: 2, Z: 3, Color: 0xFF00FF00, Flags: [2]uint8{1, 2}},
+			{X: -1, Y: -2, Z: -3, Color: 0x00FF00FF, Flags: [2]uint8{3, 4}},
+		}
+	)
+	assert.NoError(t, FixedStructSlice(&vertices, uint8(2)).Write(&buf, endian))
+	assert.NoError(t, Slice(&vertices, uint8(2), mapFixedVertex).Write(&expected, endian))
+	assert.Equal(t, expected.Bytes(), buf.Bytes())
+
+	var read []fixedVertex
+	assert.NoError(t, FixedStructSlice(&read, uint8(2)).Read(&buf, endian))
+	assert.Equal(t, vertices, read)
+
+	var invalid []struct {
+		Name string
+	}
+	assert.ErrorIs(t, FixedStructSlice(&invalid, uint8(1)).Read(&buf, endian), ErrNotFixedSize)
+
+	type unexported struct {
+		x, y float32
+	}
+	var hidden = []unexported{{x: 1, y: 2}}
+	buf.Reset()
+	buf.Write(make([]byte, 8))
+	assert.ErrorIs(t, FixedStructSlice(&hidden, uint8(1)).Read(&buf, endian), ErrNotFixedSize, "Unexported fields can't be set by binary.Read")
+	assert.ErrorIs(t, FixedStructSlice(&hidden, uint8(1)).Write(&buf, endian), ErrNotFixedSize)
+
+	type nested struct {
+		Points [2]unexported
+	}
+	var nestedHidden []nested
+	assert.ErrorIs(t, FixedStructSlice(&nestedHidden, uint8(1)).Read(&buf, endian), ErrNotFixedSize)
+
+	type padded struct {
+		X float32
+		_ [4]byte
+	}
+	var withPadding []padded
+	buf.Reset()
+	buf.Write(make([]byte, 8))
+	assert.NoError(t, FixedStructSlice(&withPadding, uint8(1)).Read(&buf, endian), "Blank fields are skipped by encoding/binary")
+}
+
+func BenchmarkFixedStructSlice(b *testing.B) {
+	var (
+		vertices = make([]fixedVertex, 10_000)
+		endian   = binary.LittleEndian
+		buf      bytes.Buffer
+	)
+	assert.NoError(b, FixedStructSlice(&vertices, uint32(len(vertices))).Write(&buf, endian))
+	data := buf.Bytes()
+	b.Run("FixedStructSlice", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := FixedStructSlice(&vertices, uint32(len(vertices))).Read(bytes.NewReader(data), endian); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Slice", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := Slice(&vertices, uint32(len(vertices)), mapFixedVertex).Read(bytes.NewReader(data), endian); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
 func TestDynamicSlice(t *testing.T) {
 	data := []int16{1, -2, 3}
 	m := DynamicSlice(&data, func(b *int16) Mapper {
@@ -70,3 +245,347 @@ func TestDynamicSlice(t *testing.T) {
 	assert.Len(t, data, 3)
 	assert.Equal(t, []int16{1, -2, 3}, data)
 }
+
+func TestMatrix(t *testing.T) {
+	var (
+		buf        bytes.Buffer
+		endian     = binary.BigEndian
+		rows, cols = uint8(2), uint8(3)
+		data       = [][]int16{
+			{1, 2, 3},
+			{4, 5, 6},
+		}
+	)
+	m := Matrix(&data, &rows, &cols, Int[int16])
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, 14, buf.Len())
+	assert.Equal(t, []byte{2, 3, 0, 1, 0, 2}, buf.Bytes()[:6])
+
+	data, rows, cols = nil, 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint8(2), rows)
+	assert.Equal(t, uint8(3), cols)
+	assert.Equal(t, [][]int16{{1, 2, 3}, {4, 5, 6}}, data)
+
+	data[1] = data[1][:2]
+	buf.Reset()
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrMatrixShape)
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestFixedMatrix(t *testing.T) {
+	var (
+		buf       bytes.Buffer
+		endian    = binary.LittleEndian
+		transform = [][]float32{
+			{1, 0, 0, 10},
+			{0, 1, 0, 20},
+			{0, 0, 1, 30},
+			{0, 0, 0, 1},
+		}
+	)
+	m := FixedMatrix(&transform, 4, 4)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, 64, buf.Len())
+	assert.Equal(t, []byte{0, 0, 0x20, 0x41}, buf.Bytes()[12:16], "Values should be in row-major order")
+
+	transform = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, [][]float32{
+		{1, 0, 0, 10},
+		{0, 1, 0, 20},
+		{0, 0, 1, 30},
+		{0, 0, 0, 1},
+	}, transform)
+
+	transform[2] = transform[2][:3]
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrMatrixShape)
+	transform = transform[:3]
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrMatrixShape)
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestStreamSlice(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		count  = uint16(1000)
+		next   uint32
+		sum    uint64
+	)
+	m := StreamSlice(&count, Int[uint32],
+		func(e uint32) error {
+			sum += uint64(e)
+			return nil
+		},
+		func() (uint32, error) {
+			next++
+			return next, nil
+		},
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, 2+4*1000, buf.Len())
+
+	count = 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(1000), count)
+	assert.Equal(t, uint64(500500), sum)
+
+	assert.ErrorIs(t, StreamSlice[uint32](&count, Int[uint32], nil, nil).Read(&buf, endian), ErrNilReadWrite)
+}
+
+func TestVarintDelimitedStream(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		data   = []string{"a", "", string(bytes.Repeat([]byte{'b'}, 200))}
+	)
+	m := VarintDelimitedStream(&data, func(s *string) Mapper {
+		return NullTermString(s)
+	})
+	assert.NoError(t, m.Write(&buf, endian))
+	out := buf.Bytes()
+	assert.Equal(t, []byte{0x02, 'a', 0, 0x01, 0}, out[:5])
+	assert.Equal(t, []byte{0xC9, 0x01}, out[5:7])
+
+	data = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []string{"a", "", string(bytes.Repeat([]byte{'b'}, 200))}, data)
+
+	buf.Reset()
+	buf.Write([]byte{0x05, 'a', 'b'})
+	assert.ErrorIs(t, m.Read(&buf, endian), io.ErrUnexpectedEOF)
+
+	buf.Reset()
+	buf.Write([]byte{0x05, 'a', 0})
+	assert.ErrorIs(t, m.Read(&buf, endian), io.ErrUnexpectedEOF, "The stream ends before the declared size, even though mapVal is satisfied")
+}
+
+func TestChannelSlice(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		ctx    = context.Background()
+		count  = uint8(3)
+		in     = make(chan uint16, 3)
+		out    = make(chan uint16)
+	)
+	in <- 1
+	in <- 2
+	in <- 3
+	close(in)
+	assert.NoError(t, ChannelSlice(ctx, &count, Int[uint16], nil, in).Write(&buf, endian))
+	assert.Equal(t, []byte{3, 0, 1, 0, 2, 0, 3}, buf.Bytes())
+
+	done := make(chan error)
+	reader := ChannelSlice[uint16](ctx, &count, Int[uint16], out, nil)
+	go func() {
+		done <- reader.Read(&buf, endian)
+	}()
+	var received []uint16
+	for e := range out {
+		received = append(received, e)
+	}
+	assert.NoError(t, <-done)
+	assert.Equal(t, []uint16{1, 2, 3}, received)
+	assert.ErrorIs(t, reader.Read(bytes.NewReader([]byte{0}), endian), ErrChanReused, "A second read shouldn't close out again")
+
+	buf.Reset()
+	count = 4
+	in = make(chan uint16)
+	close(in)
+	assert.ErrorIs(t, ChannelSlice(ctx, &count, Int[uint16], nil, in).Write(&buf, endian), ErrClosedChan)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	buf.Reset()
+	buf.Write([]byte{1, 0, 1})
+	assert.ErrorIs(t, ChannelSlice[uint16](cancelled, &count, Int[uint16], make(chan uint16), nil).Read(&buf, endian), context.Canceled)
+}
+
+func TestByteLenSlice(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		endian  = binary.BigEndian
+		byteLen uint8
+		data    = []uint32{1, 2, 3}
+	)
+	m := ByteLenSlice(&data, &byteLen, 4, Int[uint32])
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint8(12), byteLen)
+	assert.Equal(t, 13, buf.Len())
+
+	data, byteLen = nil, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []uint32{1, 2, 3}, data)
+
+	buf.Reset()
+	buf.Write([]byte{6, 0, 0, 0, 1, 0, 0})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrSizeMismatch)
+
+	assert.ErrorIs(t, ByteLenSlice(&data, &byteLen, 2, Int[uint32]).Write(&buf, endian), ErrSizeMismatch)
+	assert.ErrorIs(t, ByteLenSlice(&data, &byteLen, 0, Int[uint32]).Write(&buf, endian), ErrInvalidWidth)
+}
+
+func TestSparseSlice(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		endian   = binary.BigEndian
+		totalLen uint8
+		data     = make([]uint16, 10)
+	)
+	data[2], data[9] = 5, 7
+	m := SparseSlice(&data, &totalLen, Int[uint16])
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint8(10), totalLen)
+	assert.Equal(t, []byte{10, 2, 2, 0, 5, 9, 0, 7}, buf.Bytes())
+
+	data = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []uint16{0, 0, 5, 0, 0, 0, 0, 0, 0, 7}, data)
+
+	buf.Write([]byte{10, 1, 10, 0, 5})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrOutOfBounds)
+	buf.Reset()
+	buf.Write([]byte{1, 2})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrOutOfBounds)
+}
+
+func TestSliceUntilBytes(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		endian  = binary.BigEndian
+		names   = []string{"a", "bcd", ""}
+		byteLen uint16
+	)
+	m := SliceUntilBytes(&names, &byteLen, NullTermString)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, uint16(7), byteLen)
+	assert.Equal(t, []byte{0, 7, 'a', 0, 'b', 'c', 'd', 0, 0}, buf.Bytes())
+	buf.WriteByte(0xFF)
+
+	names, byteLen = nil, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []string{"a", "bcd", ""}, names)
+	assert.Equal(t, []byte{0xFF}, buf.Bytes(), "Data after the section should not be read")
+
+	buf.Reset()
+	buf.Write([]byte{0, 0})
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Len(t, names, 0)
+
+	var vals []uint32
+	buf.Reset()
+	buf.Write([]byte{0, 6, 0, 0, 0, 1, 0, 0, 0, 2})
+	assert.ErrorIs(t, SliceUntilBytes(&vals, &byteLen, Int[uint32]).Read(&buf, endian), io.ErrUnexpectedEOF, "An element extending past the section should fail")
+
+	buf.Reset()
+	buf.Write([]byte{0, 1, 0})
+	noop := func(*uint32) Mapper { return Any(func(io.Reader, binary.ByteOrder) error { return nil }, nil) }
+	assert.ErrorIs(t, SliceUntilBytes(&vals, &byteLen, noop).Read(&buf, endian), ErrSizeMismatch)
+}
+
+func TestDrainChannel(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		ch     = make(chan uint16, 4)
+	)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	m := DrainChannel(ch, Int[uint16])
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Len(t, ch, 0, "The channel should be drained")
+	assert.Equal(t, []byte{0, 0, 0, 3, 0, 1, 0, 2, 0, 3}, buf.Bytes())
+	data := append([]byte{}, buf.Bytes()...)
+
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(1), <-ch)
+	assert.Equal(t, uint16(2), <-ch)
+	assert.Equal(t, uint16(3), <-ch)
+
+	ch <- 9
+	ch <- 9
+	assert.ErrorIs(t, m.Read(bytes.NewReader(data), endian), ErrChanFull)
+	assert.Len(t, ch, 2, "No elements should be sent if there isn't room for all of them")
+
+	closed := make(chan uint16, 2)
+	closed <- 5
+	close(closed)
+	buf.Reset()
+	assert.NoError(t, DrainChannel(closed, Int[uint16]).Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0, 1, 0, 5}, buf.Bytes())
+}
+
+func TestAssertSorted(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		vals   = []uint16{1, 5, 9}
+		count  = uint8(3)
+		less   = func(a, b uint16) bool { return a < b }
+	)
+	m := AssertSorted(&vals, &count, less, Int[uint16])
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{3, 0, 1, 0, 5, 0, 9}, buf.Bytes())
+	vals = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []uint16{1, 5, 9}, vals)
+
+	buf.Write([]byte{3, 0, 1, 0, 9, 0, 5})
+	err := m.Read(&buf, endian)
+	assert.ErrorIs(t, err, ErrNotSorted)
+	assert.Contains(t, err.Error(), "index 2")
+	assert.Equal(t, []uint16{1, 5, 9}, vals, "Target should be unchanged on failure")
+
+	vals = []uint16{1, 1, 2}
+	buf.Reset()
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrNotSorted, "Equal elements are not strictly increasing")
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestSortedSet(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		vals   = []uint16{9, 1, 5, 1, 9}
+		count  uint8
+	)
+	m := SortedSet(&vals, &count, Int[uint16])
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{3, 0, 1, 0, 5, 0, 9}, buf.Bytes())
+	assert.Equal(t, uint8(3), count)
+	assert.Equal(t, []uint16{9, 1, 5, 1, 9}, vals, "Target should not be modified on write")
+	vals = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []uint16{1, 5, 9}, vals)
+
+	buf.Write([]byte{3, 0, 1, 0, 9, 0, 5})
+	err := m.Read(&buf, endian)
+	assert.ErrorIs(t, err, ErrNotSorted)
+	assert.Contains(t, err.Error(), "index 2")
+	assert.Equal(t, []uint16{1, 5, 9}, vals, "Target should be unchanged on failure")
+
+	buf.Reset()
+	buf.Write([]byte{3, 0, 1, 0, 5, 0, 5})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrDuplicateElem)
+
+	var (
+		names     = []string{"b", "a", "b"}
+		nameCount uint32
+	)
+	buf.Reset()
+	assert.NoError(t, SortedSet(&names, &nameCount, NullTermString).Write(&buf, endian))
+	names = nil
+	assert.NoError(t, SortedSet(&names, &nameCount, NullTermString).Read(&buf, endian))
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	var (
+		floats     = []float64{1, math.NaN(), 0}
+		floatCount uint8
+	)
+	buf.Reset()
+	assert.ErrorIs(t, SortedSet(&floats, &floatCount, Float[float64]).Write(&buf, endian), ErrNotSorted, "NaN should be rejected since it can't be read back")
+	assert.Zero(t, buf.Len())
+}
@@ -3,12 +3,20 @@ package bin
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"unicode/utf16"
 	"unicode/utf8"
 )
 
+var (
+	ErrStringTooLong   = errors.New("string exceeds maximum length")
+	ErrEmptyMultiEntry = errors.New("empty string in multi-string list")
+	ErrInvalidEscape   = errors.New("invalid escape sequence")
+)
+
 // FixedString will map a string with a max length that is known ahead of time.
 // The target string will not contain any trailing zero bytes if the encoded string is less than the space allowed.
 func FixedString(s *string, length int) Mapper {
@@ -35,8 +43,125 @@ func FixedString(s *string, length int) Mapper {
 	}
 }
 
+// FixedStringArray maps exactly count strings that are each stored in a field of width bytes, with no length prefix, as is common with fixed-layout name tables.
+// Each string is mapped like FixedString, so trailing zero bytes are trimmed on read, and zero bytes are used as padding on write.
+// On write, ErrCountMismatch is returned if target doesn't have exactly count strings, and ErrStringTooLong is returned if any string is longer than width.
+func FixedStringArray(target *[]string, count, width int) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			strs := make([]string, count)
+			for i := range strs {
+				if err := FixedString(&strs[i], width).Read(r, endian); err != nil {
+					return err
+				}
+			}
+			*target = strs
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if len(*target) != count {
+				return fmt.Errorf("%w: expected %d strings, but have %d", ErrCountMismatch, count, len(*target))
+			}
+			for i, s := range *target {
+				if len(s) > width {
+					return fmt.Errorf("%w: string at index %d is %d bytes, which exceeds the width of %d", ErrStringTooLong, i, len(s), width)
+				}
+			}
+			for i := range *target {
+				if err := FixedString(&(*target)[i], width).Write(w, endian); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// EscapedString maps a string in a fixed-width field like FixedString, where bytes in the escapes table are replaced with their escape sequence, such as a newline being written as `\n`.
+// The escape table must be unambiguous to reverse, so ErrInvalidEscape is returned if any escape sequence is empty, contains a zero byte, is a prefix of another sequence, or starts with a byte that isn't itself escaped.
+// On read, the escape sequences are replaced with their original bytes, and ErrInvalidEscape is returned if an escaped byte doesn't start a known escape sequence.
+// On write, ErrStringTooLong is returned if the escaped string doesn't fit in length bytes.
+func EscapedString(s *string, length int, escapes map[byte][]byte) Mapper {
+	if s == nil {
+		return nilMapping
+	}
+	if err := validateEscapes(escapes); err != nil {
+		return errMapping(err)
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var escaped string
+			if err := FixedString(&escaped, length).Read(r, endian); err != nil {
+				return err
+			}
+			var buf bytes.Buffer
+		outer:
+			for i := 0; i < len(escaped); {
+				if _, ok := escapes[escaped[i]]; !ok {
+					buf.WriteByte(escaped[i])
+					i++
+					continue
+				}
+				for b, seq := range escapes {
+					if strings.HasPrefix(escaped[i:], string(seq)) {
+						buf.WriteByte(b)
+						i += len(seq)
+						continue outer
+					}
+				}
+				return fmt.Errorf("%w: unknown escape sequence at offset %d", ErrInvalidEscape, i)
+			}
+			*s = buf.String()
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			for i := 0; i < len(*s); i++ {
+				if seq, ok := escapes[(*s)[i]]; ok {
+					buf.Write(seq)
+					continue
+				}
+				buf.WriteByte((*s)[i])
+			}
+			if buf.Len() > length {
+				return fmt.Errorf("%w: escaped string is %d bytes, but the field is %d bytes", ErrStringTooLong, buf.Len(), length)
+			}
+			escaped := buf.String()
+			return FixedString(&escaped, length).Write(w, endian)
+		},
+	}
+}
+
+// validateEscapes ensures that an escape table can be reversed unambiguously.
+// Since every escape sequence starts with an escaped byte, an escape sequence can never be confused with literal bytes, and since no sequence is a prefix of another, at most one sequence can match.
+func validateEscapes(escapes map[byte][]byte) error {
+	for b, seq := range escapes {
+		switch {
+		case len(seq) == 0:
+			return fmt.Errorf("%w: empty escape sequence for 0x%02X", ErrInvalidEscape, b)
+		case bytes.IndexByte(seq, 0) >= 0:
+			return fmt.Errorf("%w: escape sequence for 0x%02X contains a zero byte", ErrInvalidEscape, b)
+		}
+		if _, ok := escapes[seq[0]]; !ok {
+			return fmt.Errorf("%w: escape sequence for 0x%02X starts with 0x%02X, which is not escaped", ErrInvalidEscape, b, seq[0])
+		}
+		for other, otherSeq := range escapes {
+			if other != b && bytes.HasPrefix(otherSeq, seq) {
+				return fmt.Errorf("%w: escape sequence for 0x%02X is a prefix of the sequence for 0x%02X", ErrInvalidEscape, b, other)
+			}
+		}
+	}
+	return nil
+}
+
 // NullTermString will read and write null-byte terminated string.
 // The string should not contain a null terminator, one will be added on write.
+//
+// Note that reading will continue until a null byte or EOF is encountered, which means that a stream without a null terminator will be accumulated in memory in its entirety.
+// Use BoundedNullTermString to limit the maximum length when reading from an untrusted source.
 func NullTermString(s *string) Mapper {
 	if s == nil {
 		return nilMapping
@@ -68,6 +193,133 @@ func NullTermString(s *string) Mapper {
 	}
 }
 
+// BoundedNullTermString is the same as NullTermString, except that at most maxLen bytes will be read before a null terminator is expected.
+// If maxLen bytes are read without encountering a null terminator, then ErrStringTooLong is returned.
+// Attempting to write a string longer than maxLen bytes will also return ErrStringTooLong.
+func BoundedNullTermString(s *string, maxLen int) Mapper {
+	if s == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				buf bytes.Buffer
+				ubr = &unbufferedByteReader{reader: r}
+			)
+			for {
+				b, err := ubr.ReadByte()
+				if err != nil {
+					return err
+				}
+				if b == 0 {
+					*s = buf.String()
+					return nil
+				}
+				if buf.Len() >= maxLen {
+					return fmt.Errorf("%w: no null terminator within %d bytes", ErrStringTooLong, maxLen)
+				}
+				if err := buf.WriteByte(b); err != nil {
+					return err
+				}
+			}
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if len(*s) > maxLen {
+				return fmt.Errorf("%w: string length %d exceeds max length %d", ErrStringTooLong, len(*s), maxLen)
+			}
+			bs := append([]byte(*s), 0)
+			return binary.Write(w, endian, bs)
+		},
+	}
+}
+
+// VarintString maps a UTF-8 string prefixed with its length in bytes as an unsigned varint, as is done in Protocol Buffers.
+func VarintString(s *string) Mapper {
+	return varintString(s, -1)
+}
+
+// BoundedVarintString is the same as VarintString, except that ErrStringTooLong is returned if the length is greater than maxLen.
+// On read, the length is checked before the string is read, which protects against untrusted input declaring an excessive length.
+func BoundedVarintString(s *string, maxLen int) Mapper {
+	return varintString(s, maxLen)
+}
+
+func varintString(s *string, maxLen int) Mapper {
+	if s == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			length, err := binary.ReadUvarint(&unbufferedByteReader{reader: r})
+			if err != nil {
+				return err
+			}
+			if maxLen >= 0 && length > uint64(maxLen) {
+				return fmt.Errorf("%w: declared length %d exceeds max length %d", ErrStringTooLong, length, maxLen)
+			}
+			var buf bytes.Buffer
+			if _, err := io.CopyN(&buf, r, int64(length)); err != nil {
+				if errors.Is(err, io.EOF) {
+					return io.ErrUnexpectedEOF
+				}
+				return err
+			}
+			*s = buf.String()
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			if maxLen >= 0 && len(*s) > maxLen {
+				return fmt.Errorf("%w: string length %d exceeds max length %d", ErrStringTooLong, len(*s), maxLen)
+			}
+			buf := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(*s)), uint64(len(*s)))
+			buf = append(buf, *s...)
+			_, err := w.Write(buf)
+			return err
+		},
+	}
+}
+
+// MultiString maps a list of null-terminated strings that is terminated by an additional null byte, such as a Windows REG_MULTI_SZ value or environment block.
+// On read, strings are read until an empty string is encountered, so an empty list is represented by a single null byte.
+// Since an empty string would terminate the list, ErrEmptyMultiEntry is returned on write if the list contains an empty string.
+func MultiString(target *[]string) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return &mapper{
+		read: func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				list []string
+				s    string
+			)
+			for {
+				if err := NullTermString(&s).Read(r, endian); err != nil {
+					return err
+				}
+				if len(s) == 0 {
+					break
+				}
+				list = append(list, s)
+			}
+			*target = list
+			return nil
+		},
+		write: func(w io.Writer, endian binary.ByteOrder) error {
+			var buf bytes.Buffer
+			for i, s := range *target {
+				if len(s) == 0 {
+					return fmt.Errorf("%w: at index %d", ErrEmptyMultiEntry, i)
+				}
+				buf.WriteString(s)
+				buf.WriteByte(0)
+			}
+			buf.WriteByte(0)
+			_, err := buf.WriteTo(w)
+			return err
+		},
+	}
+}
+
 // Uni16NullTermString is the same as NullTermString, except that it works with UTF-16 strings.
 func Uni16NullTermString(s *string) Mapper {
 	if s == nil {
@@ -141,3 +393,39 @@ func Uni16FixedString(s *string, wcharlen int) Mapper {
 		},
 	}
 }
+
+// ErrorField maps an error value as a uint32 code followed by its message as a VarintString, which is useful for RPC style protocols that transmit errors.
+// On write, the code is produced with codeOf, and a nil error is written as a zero code with an empty message.
+// On read, a zero code with an empty message results in a nil error, and anything else is reconstructed with fromCode.
+func ErrorField(target *error, codeOf func(error) uint32, fromCode func(code uint32, msg string) error) Mapper {
+	if target == nil || codeOf == nil || fromCode == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var (
+				code uint32
+				msg  string
+			)
+			if err := MapSequence(Int(&code), VarintString(&msg)).Read(r, endian); err != nil {
+				return err
+			}
+			if code == 0 && len(msg) == 0 {
+				*target = nil
+				return nil
+			}
+			*target = fromCode(code, msg)
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var (
+				code uint32
+				msg  string
+			)
+			if *target != nil {
+				code, msg = codeOf(*target), (*target).Error()
+			}
+			return MapSequence(Int(&code), VarintString(&msg)).Write(w, endian)
+		},
+	)
+}
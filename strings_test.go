@@ -3,6 +3,7 @@ package bin
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -100,3 +101,182 @@ func TestUni16NullTermString(t *testing.T) {
 	assert.Equal(t, "Hi", s1)
 	assert.Equal(t, "Hi", s2)
 }
+
+func TestBoundedNullTermString(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+	)
+	s := "Hi"
+	m := BoundedNullTermString(&s, 2)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{'H', 'i', 0}, buf.Bytes())
+
+	s = ""
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, "Hi", s)
+
+	buf.Reset()
+	buf.Write([]byte{'H', 'e', 'y', 0})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrStringTooLong)
+
+	s = "Hey"
+	buf.Reset()
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrStringTooLong)
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestVarintString(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		long   = string(bytes.Repeat([]byte{'a'}, 300))
+		s1     = "Hi"
+		s2     = long
+	)
+	m := MapSequence(
+		VarintString(&s1),
+		VarintString(&s2),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	out := buf.Bytes()
+	assert.Equal(t, []byte{0x02, 'H', 'i', 0xAC, 0x02}, out[:5])
+	assert.Equal(t, 305, len(out))
+
+	s1, s2 = "", ""
+	assert.NoError(t, m.Read(bytes.NewReader(out), endian))
+	assert.Equal(t, "Hi", s1)
+	assert.Equal(t, long, s2)
+
+	bounded := BoundedVarintString(&s1, 100)
+	assert.ErrorIs(t, bounded.Read(bytes.NewReader(out[3:]), endian), ErrStringTooLong)
+	s1 = long
+	assert.ErrorIs(t, bounded.Write(&buf, endian), ErrStringTooLong)
+}
+
+func TestMultiString(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		list   = []string{"A=1", "B=2"}
+	)
+	m := MultiString(&list)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "A=1\x00B=2\x00\x00", buf.String())
+
+	list = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []string{"A=1", "B=2"}, list)
+
+	list = nil
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0}, buf.Bytes(), "An empty list should be a single null")
+	list = []string{"x"}
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Empty(t, list)
+
+	list = []string{"A", "", "B"}
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrEmptyMultiEntry)
+}
+
+func TestErrorField(t *testing.T) {
+	var (
+		buf      bytes.Buffer
+		endian   = binary.BigEndian
+		notFound = errors.New("not found")
+		target   error
+	)
+	codeOf := func(err error) uint32 {
+		if errors.Is(err, notFound) {
+			return 404
+		}
+		return 500
+	}
+	fromCode := func(code uint32, msg string) error {
+		if code == 404 {
+			return notFound
+		}
+		return errors.New(msg)
+	}
+	m := ErrorField(&target, codeOf, fromCode)
+
+	target = notFound
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 1, 0x94, 9, 'n', 'o', 't', ' ', 'f', 'o', 'u', 'n', 'd'}, buf.Bytes())
+	target = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.ErrorIs(t, target, notFound)
+
+	target = errors.New("boom")
+	assert.NoError(t, m.Write(&buf, endian))
+	target = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.EqualError(t, target, "boom")
+
+	target = nil
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0, 0, 0}, buf.Bytes())
+	target = notFound
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.NoError(t, target)
+}
+
+func TestFixedStringArray(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		names  = []string{"alpha", "", "gamma"}
+	)
+	m := FixedStringArray(&names, 3, 6)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte("alpha\x00\x00\x00\x00\x00\x00\x00gamma\x00"), buf.Bytes())
+
+	names = nil
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, []string{"alpha", "", "gamma"}, names)
+
+	names = []string{"alpha", "beta"}
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrCountMismatch)
+	names = []string{"alpha", "beta", "epsilon"}
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrStringTooLong)
+	assert.Equal(t, 0, buf.Len(), "Nothing should be written if validation fails")
+}
+
+func TestEscapedString(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		endian  = binary.BigEndian
+		s       = "a\tb\\c\n"
+		escapes = map[byte][]byte{
+			'\n': []byte(`\n`),
+			'\t': []byte(`\t`),
+			'\\': []byte(`\\`),
+		}
+	)
+	m := EscapedString(&s, 12, escapes)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "a\\tb\\\\c\\n\x00\x00\x00", buf.String())
+
+	s = ""
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, "a\tb\\c\n", s)
+
+	buf.Reset()
+	buf.WriteString(`a\qb`)
+	assert.ErrorIs(t, EscapedString(&s, 4, escapes).Read(&buf, endian), ErrInvalidEscape)
+
+	s = "\n\n\n"
+	assert.ErrorIs(t, EscapedString(&s, 5, escapes).Write(&buf, endian), ErrStringTooLong)
+
+	tests := map[string]map[byte][]byte{
+		"Empty sequence":        {'\n': nil},
+		"Zero byte":             {'\\': {'\\', 0}},
+		"Unescaped first byte":  {'\n': []byte(`\n`)},
+		"Prefix of another one": {'\\': []byte(`\`), '\n': []byte(`\n`)},
+	}
+	for name, table := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, EscapedString(&s, 4, table).Write(&buf, endian), ErrInvalidEscape)
+		})
+	}
+}
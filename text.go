@@ -0,0 +1,283 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrTextOverflow = errors.New("formatted value is too wide for the field")
+	ErrInvalidText  = errors.New("invalid numeric text")
+	ErrEmptyDelim   = errors.New("empty delimiter")
+)
+
+// readTextField reads a fixed-width text field, trimming any surrounding spaces.
+func readTextField(r io.Reader, width int) (string, error) {
+	buf := make([]byte, width)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(buf)), nil
+}
+
+// writeTextField writes text right-justified in a fixed-width field, padded with pad.
+func writeTextField(w io.Writer, text string, width int, pad byte) error {
+	if len(text) > width {
+		return fmt.Errorf("%w: %q is wider than %d bytes", ErrTextOverflow, text, width)
+	}
+	buf := bytes.Repeat([]byte{pad}, width)
+	copy(buf[width-len(text):], text)
+	_, err := w.Write(buf)
+	return err
+}
+
+func isSigned[T AnyInt]() bool {
+	var zero T
+	return zero-1 < zero
+}
+
+// NumericText maps an integer as right-justified, space-padded decimal text in a field that is width bytes wide.
+// This is common in legacy fixed-column formats.
+// ErrTextOverflow is returned on write if the formatted integer doesn't fit in the field, and ErrInvalidText is returned on read if the field is not a valid integer.
+func NumericText[T AnyInt](target *T, width int) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	bitSize := binary.Size(*target) * 8
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			text, err := readTextField(r, width)
+			if err != nil {
+				return err
+			}
+			if isSigned[T]() {
+				val, err := strconv.ParseInt(text, 10, bitSize)
+				if err != nil {
+					return fmt.Errorf("%w: %v", ErrInvalidText, err)
+				}
+				*target = T(val)
+				return nil
+			}
+			val, err := strconv.ParseUint(text, 10, bitSize)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidText, err)
+			}
+			*target = T(val)
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			var text string
+			if isSigned[T]() {
+				text = strconv.FormatInt(int64(*target), 10)
+			} else {
+				text = strconv.FormatUint(uint64(*target), 10)
+			}
+			return writeTextField(w, text, width, ' ')
+		},
+	)
+}
+
+// NumericTextFloat maps a floating point value as right-justified, space-padded text in a field that is width bytes wide.
+// The format and precision are used as in strconv.FormatFloat when writing.
+// ErrTextOverflow is returned on write if the formatted value doesn't fit in the field, and ErrInvalidText is returned on read if the field is not a valid number.
+func NumericTextFloat[T AnyFloat](target *T, width int, format byte, prec int) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	bitSize := binary.Size(*target) * 8
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			text, err := readTextField(r, width)
+			if err != nil {
+				return err
+			}
+			val, err := strconv.ParseFloat(text, bitSize)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidText, err)
+			}
+			*target = T(val)
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			text := strconv.FormatFloat(float64(*target), format, prec, bitSize)
+			return writeTextField(w, text, width, ' ')
+		},
+	)
+}
+
+// ImpliedDecimalText maps a number as zero-padded decimal text in a field that is width bytes wide, with an implied decimal point before the last decimals digits.
+// For example, 12.34 with a width of 8 and 2 decimals is written as "00001234", as is common in banking formats like NACHA.
+// Negative values are written with a leading '-' before the zero padding.
+// On read, leading and trailing spaces are also accepted.
+// ErrTextOverflow is returned on write if the scaled value doesn't fit in the field, and ErrInvalidText is returned on read if the field is not a valid integer.
+func ImpliedDecimalText(target *float64, width, decimals int) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	scale := math.Pow10(decimals)
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			text, err := readTextField(r, width)
+			if err != nil {
+				return err
+			}
+			val, err := strconv.ParseInt(text, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidText, err)
+			}
+			*target = float64(val) / scale
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			scaled := math.Round(*target * scale)
+			if math.IsNaN(scaled) || math.IsInf(scaled, 0) || math.Abs(scaled) >= math.MaxInt64 {
+				return fmt.Errorf("%w: %v cannot be represented", ErrTextOverflow, *target)
+			}
+			val := int64(scaled)
+			var sign string
+			if val < 0 {
+				sign = "-"
+				val = -val
+			}
+			digits := strconv.FormatInt(val, 10)
+			if len(sign)+len(digits) > width {
+				return fmt.Errorf("%w: %s%s is wider than %d bytes", ErrTextOverflow, sign, digits, width)
+			}
+			_, err := io.WriteString(w, sign+strings.Repeat("0", width-len(sign)-len(digits))+digits)
+			return err
+		},
+	)
+}
+
+// parseHex parses hexadecimal text as a T, where signed types are parsed as their two's complement bit pattern.
+func parseHex[T AnyInt](text string, bitSize int) (T, error) {
+	val, err := strconv.ParseUint(text, 16, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidText, err)
+	}
+	return T(val), nil
+}
+
+// formatHex formats an integer as lowercase hexadecimal text, where signed types are formatted as their two's complement bit pattern.
+func formatHex[T AnyInt](val T, bitSize int) string {
+	return strconv.FormatUint(uint64(val)&(math.MaxUint64>>(64-bitSize)), 16)
+}
+
+// HexText maps an integer as zero-padded hexadecimal text in a field that is width bytes wide.
+// Signed integers are mapped as their two's complement bit pattern, so an int8 of -1 is written as "ff".
+// Lowercase hex digits are written, and either case is accepted on read.
+// ErrTextOverflow is returned on write if the formatted integer doesn't fit in the field, and ErrInvalidText is returned on read if the field contains anything other than hex digits.
+func HexText[T AnyInt](target *T, width int) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	bitSize := binary.Size(*target) * 8
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			buf := make([]byte, width)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return err
+			}
+			val, err := parseHex[T](string(buf), bitSize)
+			if err != nil {
+				return err
+			}
+			*target = val
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			return writeTextField(w, formatHex(*target, bitSize), width, '0')
+		},
+	)
+}
+
+// DelimitedHexText maps an integer as variable-width hexadecimal text followed by delim, like the chunk sizes in HTTP chunked transfer encoding that are terminated by "\r\n".
+// On read, at most as many hex digits as are needed for T are read before delim is expected, so a missing delimiter can't cause unbounded reads.
+// ErrInvalidText is returned on read if the text before delim is empty or contains anything other than hex digits.
+// On write, the integer is written with no padding, followed by delim.
+// ErrEmptyDelim is returned if delim is empty.
+func DelimitedHexText[T AnyInt](target *T, delim []byte) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	if len(delim) == 0 {
+		return errMapping(ErrEmptyDelim)
+	}
+	bitSize := binary.Size(*target) * 8
+	maxLen := bitSize/4 + len(delim)
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			var (
+				buf []byte
+				ubr = &unbufferedByteReader{reader: r}
+			)
+			for !bytes.HasSuffix(buf, delim) {
+				if len(buf) >= maxLen {
+					return fmt.Errorf("%w: delimiter not found within %d bytes", ErrInvalidText, maxLen)
+				}
+				b, err := ubr.ReadByte()
+				if err != nil {
+					if errors.Is(err, io.EOF) && len(buf) > 0 {
+						return io.ErrUnexpectedEOF
+					}
+					return err
+				}
+				buf = append(buf, b)
+			}
+			val, err := parseHex[T](string(buf[:len(buf)-len(delim)]), bitSize)
+			if err != nil {
+				return err
+			}
+			*target = val
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			if _, err := io.WriteString(w, formatHex(*target, bitSize)); err != nil {
+				return err
+			}
+			_, err := w.Write(delim)
+			return err
+		},
+	)
+}
+
+// HexDigest maps byteLen raw bytes, like a hash or an ID, that are held as a lowercase hexadecimal string in the target.
+// On write, ErrSizeMismatch is returned if the string isn't exactly 2*byteLen characters, and ErrInvalidText is returned if it contains anything other than hex digits.
+// Either case is accepted on write, but the string is always lowercase after a read.
+// ErrInvalidLength is returned if byteLen is not positive.
+func HexDigest(target *string, byteLen int) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	if byteLen <= 0 {
+		return errMapping(fmt.Errorf("%w: digest length %d", ErrInvalidLength, byteLen))
+	}
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			buf := make([]byte, byteLen)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return err
+			}
+			*target = hex.EncodeToString(buf)
+			return nil
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			if len(*target) != 2*byteLen {
+				return fmt.Errorf("%w: expected %d hex digits, but found %d", ErrSizeMismatch, 2*byteLen, len(*target))
+			}
+			buf, err := hex.DecodeString(*target)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidText, err)
+			}
+			_, err = w.Write(buf)
+			return err
+		},
+	)
+}
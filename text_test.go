@@ -0,0 +1,184 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestNumericText(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		a      = int16(-42)
+		b      = uint8(7)
+	)
+	m := MapSequence(
+		NumericText(&a, 5),
+		NumericText(&b, 3),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "  -42  7", buf.String())
+
+	a, b = 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, int16(-42), a)
+	assert.Equal(t, uint8(7), b)
+
+	a = -12345
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrTextOverflow)
+
+	buf.Reset()
+	buf.WriteString("  4x2  7")
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidText)
+
+	buf.Reset()
+	buf.WriteString("    1300")
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidText, "Values out of range for the type should be rejected")
+}
+
+func TestNumericTextFloat(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		f      = 3.14159
+	)
+	m := NumericTextFloat(&f, 8, 'f', 3)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "   3.142", buf.String())
+
+	f = 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, 3.142, f)
+}
+
+func TestImpliedDecimalText(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		amt    = 12.34
+		neg    = -0.5
+	)
+	m := MapSequence(
+		ImpliedDecimalText(&amt, 8, 2),
+		ImpliedDecimalText(&neg, 6, 2),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "00001234-00050", buf.String())
+
+	amt, neg = 0, 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, 12.34, amt)
+	assert.Equal(t, -0.5, neg)
+
+	buf.WriteString("    1234   -50")
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, 12.34, amt)
+	assert.Equal(t, -0.5, neg)
+
+	amt = 1_000_000
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrTextOverflow)
+
+	buf.Reset()
+	buf.WriteString("0000012.-00050")
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidText)
+}
+
+func TestHexText(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		val    = uint16(0xBEEF)
+	)
+	m := HexText(&val, 6)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "00beef", buf.String())
+	val = 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(0xBEEF), val)
+
+	buf.WriteString("00BEEF")
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(0xBEEF), val)
+
+	buf.WriteString("00BEEG")
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidText)
+	buf.Reset()
+	buf.WriteString("0x00ff")
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidText)
+
+	buf.Reset()
+	assert.ErrorIs(t, HexText(&val, 3).Write(&buf, endian), ErrTextOverflow)
+
+	signed := int8(-1)
+	assert.NoError(t, HexText(&signed, 2).Write(&buf, endian))
+	assert.Equal(t, "ff", buf.String())
+	signed = 0
+	assert.NoError(t, HexText(&signed, 2).Read(&buf, endian))
+	assert.Equal(t, int8(-1), signed)
+}
+
+func TestDelimitedHexText(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		size   = uint32(0x1A2F)
+		crlf   = []byte("\r\n")
+	)
+	m := DelimitedHexText(&size, crlf)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, "1a2f\r\n", buf.String())
+	size = 0
+	buf.WriteString("rest")
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint32(0x1A2F), size)
+	assert.Equal(t, "rest", buf.String(), "Only the hex text and delimiter should be consumed")
+
+	buf.Reset()
+	buf.WriteString("0\r\n")
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint32(0), size)
+
+	buf.Reset()
+	buf.WriteString("123456789\r\n")
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidText, "Too many digits for a uint32")
+
+	buf.Reset()
+	buf.WriteString("\r\n")
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidText, "Empty text is invalid")
+
+	buf.Reset()
+	buf.WriteString("12")
+	assert.ErrorIs(t, m.Read(&buf, endian), io.ErrUnexpectedEOF)
+
+	assert.ErrorIs(t, DelimitedHexText(&size, nil).Write(&buf, endian), ErrEmptyDelim)
+}
+
+func TestHexDigest(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		digest = "DEADbeef00"
+	)
+	m := HexDigest(&digest, 5)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0xDE, 0xAD, 0xBE, 0xEF, 0x00}, buf.Bytes())
+	digest = ""
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, "deadbeef00", digest, "Digests should be read as lowercase hex")
+
+	digest = "deadbeef"
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrSizeMismatch)
+	digest = "deadbeef000"
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrSizeMismatch)
+	digest = "deadbeefzz"
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrInvalidText)
+	assert.Equal(t, 0, buf.Len(), "Nothing should be written for an invalid digest")
+
+	buf.Write([]byte{1, 2, 3})
+	assert.ErrorIs(t, m.Read(&buf, endian), io.ErrUnexpectedEOF)
+
+	assert.ErrorIs(t, HexDigest(&digest, 0).Write(&buf, endian), ErrInvalidLength)
+}
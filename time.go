@@ -0,0 +1,127 @@
+package bin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	ErrTimeRange   = errors.New("time is outside of the range of the encoding")
+	ErrInvalidTime = errors.New("invalid encoded time")
+)
+
+const dosEpochYear = 1980
+
+// DOSDateTime maps a time.Time as an MS-DOS packed time word followed by a packed date word, as used in FAT directory entries and ZIP headers.
+// The time word packs the hour (5 bits), minute (6 bits), and second / 2 (5 bits), so seconds have a 2-second granularity and are truncated on write.
+// The date word packs the year since 1980 (7 bits), month (4 bits), and day (5 bits), so only years 1980 through 2107 can be represented.
+// DOS times have no time zone, so the wall clock fields of the time are written as-is, and the time is read in UTC.
+//
+// The zero time.Time is written as zero words, and zero words are read as the zero time.Time.
+// ErrTimeRange is returned on write if the year can't be represented, and ErrInvalidTime is returned on read if a field is out of range.
+func DOSDateTime(t *time.Time) Mapper {
+	if t == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var dosTime, dosDate uint16
+			if err := MapSequence(Int(&dosTime), Int(&dosDate)).Read(r, endian); err != nil {
+				return err
+			}
+			if dosTime == 0 && dosDate == 0 {
+				*t = time.Time{}
+				return nil
+			}
+			var (
+				year   = int(dosDate>>9) + dosEpochYear
+				month  = int(dosDate >> 5 & 0x0F)
+				day    = int(dosDate & 0x1F)
+				hour   = int(dosTime >> 11)
+				minute = int(dosTime >> 5 & 0x3F)
+				second = int(dosTime&0x1F) * 2
+			)
+			if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || second > 59 {
+				return fmt.Errorf("%w: DOS date 0x%04X, time 0x%04X", ErrInvalidTime, dosDate, dosTime)
+			}
+			// Day 0 of the next month is the last day of this month, which accounts for leap years.
+			// Otherwise, time.Date would silently normalize a date like February 31st into March.
+			if daysInMonth := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day(); day > daysInMonth {
+				return fmt.Errorf("%w: day %d is past the end of %s %d", ErrInvalidTime, day, time.Month(month), year)
+			}
+			*t = time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC)
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var dosTime, dosDate uint16
+			if !t.IsZero() {
+				year := t.Year()
+				if year < dosEpochYear || year > dosEpochYear+0x7F {
+					return fmt.Errorf("%w: year %d is not within %d-%d", ErrTimeRange, year, dosEpochYear, dosEpochYear+0x7F)
+				}
+				dosDate = uint16(year-dosEpochYear)<<9 | uint16(t.Month())<<5 | uint16(t.Day())
+				dosTime = uint16(t.Hour())<<11 | uint16(t.Minute())<<5 | uint16(t.Second()/2)
+			}
+			return MapSequence(Int(&dosTime), Int(&dosDate)).Write(w, endian)
+		},
+	)
+}
+
+const (
+	// ntpUnixOffset is the number of seconds between the NTP epoch (1900-01-01) and the Unix epoch (1970-01-01).
+	ntpUnixOffset = 2208988800
+	// ntpEra1 is the number of seconds in an NTP era, since the seconds field wraps in 2036.
+	ntpEra1 = 1 << 32
+)
+
+// NTPTime maps a time.Time as a 64-bit NTP timestamp, which is 32 bits of seconds since 1900-01-01 UTC followed by 32 bits of fractional seconds.
+// Since the seconds field wraps in 2036, seconds values with the most significant bit clear are interpreted as being after the wrap, as recommended in RFC 4330.
+// This means that times from 1968-01-20 03:14:08 UTC through 2104-02-26 09:42:23 UTC can be represented.
+// The fractional seconds have a resolution of about 233 picoseconds, so nanoseconds round-trip exactly.
+//
+// The zero time.Time is written as a zero timestamp, which NTP uses to mean an unknown time, and a zero timestamp is read as the zero time.Time.
+// Note that this means the exact instant of the 2036 wrap, 2036-02-07 06:28:16 UTC, is read as the zero time.Time.
+// ErrTimeRange is returned on write if the time can't be represented.
+func NTPTime(t *time.Time) Mapper {
+	if t == nil {
+		return nilMapping
+	}
+	var (
+		minSeconds int64 = 1 << 31
+		maxSeconds int64 = ntpEra1 + 1<<31 - 1
+	)
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var secs, frac uint32
+			if err := MapSequence(Int(&secs), Int(&frac)).Read(r, endian); err != nil {
+				return err
+			}
+			if secs == 0 && frac == 0 {
+				*t = time.Time{}
+				return nil
+			}
+			ntpSecs := int64(secs)
+			if secs < 1<<31 {
+				ntpSecs += ntpEra1
+			}
+			nanos := (uint64(frac)*uint64(time.Second) + 1<<31) >> 32
+			*t = time.Unix(ntpSecs-ntpUnixOffset, int64(nanos)).UTC()
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			var secs, frac uint32
+			if !t.IsZero() {
+				ntpSecs := t.Unix() + ntpUnixOffset
+				if ntpSecs < minSeconds || ntpSecs > maxSeconds {
+					return fmt.Errorf("%w: %s is not within the NTP timestamp range", ErrTimeRange, t.UTC())
+				}
+				secs = uint32(ntpSecs)
+				frac = uint32((uint64(t.Nanosecond()) << 32) / uint64(time.Second))
+			}
+			return MapSequence(Int(&secs), Int(&frac)).Write(w, endian)
+		},
+	)
+}
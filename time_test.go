@@ -0,0 +1,116 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDOSDateTime(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.LittleEndian
+		val    = time.Date(2009, time.February, 13, 23, 31, 31, 500, time.UTC)
+	)
+	m := DOSDateTime(&val)
+	assert.NoError(t, m.Write(&buf, endian))
+	// 23:31:30 = 10111 011111 01111, 2009-02-13 = 0011101 0010 01101
+	assert.Equal(t, []byte{0xEF, 0xBB, 0x4D, 0x3A}, buf.Bytes())
+
+	val = time.Time{}
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, time.Date(2009, time.February, 13, 23, 31, 30, 0, time.UTC), val, "Seconds should be truncated to 2-second granularity")
+
+	for _, tc := range []time.Time{
+		time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2107, time.December, 31, 23, 59, 58, 0, time.UTC),
+	} {
+		val = tc
+		assert.NoError(t, m.Write(&buf, endian))
+		val = time.Time{}
+		assert.NoError(t, m.Read(&buf, endian))
+		assert.Equal(t, tc, val)
+	}
+
+	val = time.Time{}
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0, 0}, buf.Bytes())
+	val = time.Now()
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.True(t, val.IsZero())
+
+	val = time.Date(1979, time.December, 31, 0, 0, 0, 0, time.UTC)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrTimeRange)
+	val = time.Date(2108, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrTimeRange)
+
+	buf.Reset()
+	buf.Write([]byte{0, 0, 0x01, 0x00})
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidTime, "Month zero is invalid")
+
+	dosDate := func(year, month, day int) []byte {
+		var (
+			out  bytes.Buffer
+			tm   uint16
+			date = uint16(year-1980)<<9 | uint16(month)<<5 | uint16(day)
+		)
+		_ = MapSequence(Int(&tm), Int(&date)).Write(&out, endian)
+		return out.Bytes()
+	}
+	buf.Reset()
+	buf.Write(dosDate(2021, 2, 31))
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidTime, "February 31st shouldn't be normalized to March")
+	buf.Write(dosDate(2021, 4, 31))
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidTime, "April has 30 days")
+	buf.Write(dosDate(2021, 2, 29))
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrInvalidTime, "2021 is not a leap year")
+	buf.Write(dosDate(2020, 2, 29))
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC), val)
+}
+
+func TestNTPTime(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		endian = binary.BigEndian
+		val    = time.Date(1970, time.January, 1, 0, 0, 0, 500_000_000, time.UTC)
+	)
+	m := NTPTime(&val)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0x83, 0xAA, 0x7E, 0x80, 0x80, 0, 0, 0}, buf.Bytes(), "The Unix epoch is 2208988800 seconds after the NTP epoch")
+	val = time.Time{}
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, time.Date(1970, time.January, 1, 0, 0, 0, 500_000_000, time.UTC), val)
+
+	for _, tc := range []time.Time{
+		time.Date(2024, time.March, 1, 12, 30, 45, 123456789, time.UTC),
+		time.Date(2036, time.February, 7, 6, 28, 16, 1, time.UTC),
+		time.Date(2100, time.January, 1, 0, 0, 0, 999999999, time.UTC),
+		time.Date(1968, time.January, 20, 3, 14, 8, 0, time.UTC),
+	} {
+		val = tc
+		assert.NoError(t, m.Write(&buf, endian))
+		val = time.Time{}
+		assert.NoError(t, m.Read(&buf, endian))
+		assert.Equal(t, tc, val)
+	}
+
+	val = time.Date(2036, time.February, 7, 6, 28, 17, 0, time.UTC)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 0, 0, 1, 0, 0, 0, 0}, buf.Bytes()[:8], "The seconds field wraps in 2036")
+	buf.Reset()
+
+	val = time.Time{}
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, make([]byte, 8), buf.Bytes())
+	val = time.Now()
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.True(t, val.IsZero())
+
+	val = time.Date(1960, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrTimeRange)
+	val = time.Date(2105, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.ErrorIs(t, m.Write(&buf, endian), ErrTimeRange)
+}
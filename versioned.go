@@ -0,0 +1,77 @@
+package bin
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// VersionedField is a field of a VersionedFields record that was added in a particular version of a format, and should be created with FieldSince.
+type VersionedField struct {
+	since  uint16
+	mapper Mapper
+	reset  func()
+}
+
+// FieldSince creates a VersionedField that was added in the given version, and is mapped to target with m.
+// When reading a record from an earlier version, target is set to its zero value.
+// If target is nil, then VersionedFields will return ErrNilReadWrite when mapped, the same as for a nil Mapper.
+func FieldSince[T any](since uint16, target *T, m Mapper) VersionedField {
+	if target == nil {
+		return VersionedField{since: since}
+	}
+	return VersionedField{
+		since:  since,
+		mapper: m,
+		reset: func() {
+			var zero T
+			*target = zero
+		},
+	}
+}
+
+// VersionedFields maps a record whose fields have been added over several versions of a format, prefixed with the record's uint16 version.
+// On read, the version is read first, and then only the fields that were added in or before that version are read in order.
+// Fields added in later versions are set to their zero value.
+// On write, version is the target version, and the fields that were added in or before the target version are written in order.
+// This allows new software to read records written by older software, and to write records that older software can read.
+func VersionedFields(version *uint16, fields ...VersionedField) Mapper {
+	if version == nil {
+		return nilMapping
+	}
+	for _, f := range fields {
+		if f.mapper == nil {
+			return nilMapping
+		}
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			if err := Int(version).Read(r, endian); err != nil {
+				return err
+			}
+			for _, f := range fields {
+				if f.since > *version {
+					f.reset()
+					continue
+				}
+				if err := f.mapper.Read(r, endian); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			if err := Int(version).Write(w, endian); err != nil {
+				return err
+			}
+			for _, f := range fields {
+				if f.since > *version {
+					continue
+				}
+				if err := f.mapper.Write(w, endian); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
@@ -0,0 +1,57 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVersionedFields(t *testing.T) {
+	type user struct {
+		name    string
+		age     uint8
+		email   string
+		premium bool
+	}
+	var (
+		buf     bytes.Buffer
+		endian  = binary.BigEndian
+		version uint16
+		u       = user{name: "gopher", age: 13, email: "g@go.dev", premium: true}
+	)
+	m := VersionedFields(&version,
+		FieldSince(1, &u.name, NullTermString(&u.name)),
+		FieldSince(1, &u.age, Byte(&u.age)),
+		FieldSince(2, &u.email, NullTermString(&u.email)),
+		FieldSince(3, &u.premium, Bool(&u.premium)),
+	)
+
+	version = 1
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{0, 1, 'g', 'o', 'p', 'h', 'e', 'r', 0, 13}, buf.Bytes())
+	version = 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(1), version)
+	assert.Equal(t, user{name: "gopher", age: 13}, u, "Fields from later versions should be zeroed")
+
+	u.email, u.premium = "g@go.dev", true
+	version = 3
+	assert.NoError(t, m.Write(&buf, endian))
+	u = user{}
+	version = 0
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, uint16(3), version)
+	assert.Equal(t, user{name: "gopher", age: 13, email: "g@go.dev", premium: true}, u)
+
+	version = 2
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, 19, buf.Len())
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.False(t, u.premium)
+
+	buf.Reset()
+	buf.Write([]byte{0, 1})
+	m = VersionedFields(&version, FieldSince[uint8](2, nil, Byte(&u.age)))
+	assert.ErrorIs(t, m.Read(&buf, endian), ErrNilReadWrite, "A nil target should not panic when it's reset")
+}
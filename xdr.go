@@ -0,0 +1,129 @@
+package bin
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// xdrPadding returns the number of zero bytes needed to pad n bytes to a 4-byte boundary.
+func xdrPadding(n uint32) int64 {
+	return int64((4 - n%4) % 4)
+}
+
+type xdrInt interface {
+	int32 | uint32 | int64 | uint64
+}
+
+// XDRInt maps an XDR (RFC 4506) integer, unsigned integer, hyper integer, or unsigned hyper integer, depending on the type of target.
+// XDR is always big-endian, regardless of the endian policy.
+func XDRInt[T xdrInt](target *T) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return OverrideEndian(Int(target), binary.BigEndian)
+}
+
+// XDRBool maps an XDR boolean, which is encoded as a 4-byte integer that is either 0 or 1.
+func XDRBool(target *bool) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return OverrideEndian(Bool32(target), binary.BigEndian)
+}
+
+// XDRFixedOpaque maps XDR fixed-length opaque data of length bytes, followed by zero padding to a 4-byte boundary.
+// On read, the padding is skipped.
+// On write, target is truncated or zero-filled to length bytes.
+func XDRFixedOpaque(target *[]byte, length uint32) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return MapSequence(
+		FixedBytes(target, length),
+		padding(xdrPadding(length)),
+	)
+}
+
+// XDROpaque maps XDR variable-length opaque data, which is prefixed with its uint32 length and followed by zero padding to a 4-byte boundary.
+// If maxLen is not zero, then ErrStringTooLong is returned if the data is longer than maxLen, as with an XDR declaration like "opaque data<maxLen>".
+func XDROpaque(target *[]byte, maxLen uint32) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			var length uint32
+			if err := XDRInt(&length).Read(r, binary.BigEndian); err != nil {
+				return err
+			}
+			if maxLen > 0 && length > maxLen {
+				return fmt.Errorf("%w: length %d exceeds the maximum of %d", ErrStringTooLong, length, maxLen)
+			}
+			return XDRFixedOpaque(target, length).Read(r, binary.BigEndian)
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			length, err := toSize[uint32](len(*target))
+			if err != nil {
+				return err
+			}
+			if maxLen > 0 && length > maxLen {
+				return fmt.Errorf("%w: length %d exceeds the maximum of %d", ErrStringTooLong, length, maxLen)
+			}
+			return MapSequence(XDRInt(&length), XDRFixedOpaque(target, length)).Write(w, binary.BigEndian)
+		},
+	)
+}
+
+// XDRString maps an XDR string, which is encoded the same way as variable-length opaque data.
+// If maxLen is not zero, then ErrStringTooLong is returned if the string is longer than maxLen bytes.
+func XDRString(target *string, maxLen uint32) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, endian binary.ByteOrder) error {
+			var buf []byte
+			if err := XDROpaque(&buf, maxLen).Read(r, endian); err != nil {
+				return err
+			}
+			*target = string(buf)
+			return nil
+		},
+		func(w io.Writer, endian binary.ByteOrder) error {
+			buf := []byte(*target)
+			return XDROpaque(&buf, maxLen).Write(w, endian)
+		},
+	)
+}
+
+// XDRArray maps an XDR variable-length array, which is prefixed with its uint32 element count.
+// Each element is mapped with the Mapper returned from mapVal, which should use XDR mappers so every element is a multiple of 4 bytes.
+// If maxLen is not zero, then ErrOutOfBounds is returned if there are more than maxLen elements, as with an XDR declaration like "int values<maxLen>".
+func XDRArray[E any](target *[]E, maxLen uint32, mapVal func(*E) Mapper) Mapper {
+	if target == nil {
+		return nilMapping
+	}
+	return Any(
+		func(r io.Reader, _ binary.ByteOrder) error {
+			var count uint32
+			if err := XDRInt(&count).Read(r, binary.BigEndian); err != nil {
+				return err
+			}
+			if maxLen > 0 && count > maxLen {
+				return fmt.Errorf("%w: %d elements exceeds the maximum of %d", ErrOutOfBounds, count, maxLen)
+			}
+			return Slice(target, count, mapVal).Read(r, binary.BigEndian)
+		},
+		func(w io.Writer, _ binary.ByteOrder) error {
+			count, err := toSize[uint32](len(*target))
+			if err != nil {
+				return err
+			}
+			if maxLen > 0 && count > maxLen {
+				return fmt.Errorf("%w: %d elements exceeds the maximum of %d", ErrOutOfBounds, count, maxLen)
+			}
+			return MapSequence(XDRInt(&count), Slice(target, count, mapVal)).Write(w, binary.BigEndian)
+		},
+	)
+}
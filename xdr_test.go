@@ -0,0 +1,71 @@
+package bin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestXDR(t *testing.T) {
+	type file struct {
+		name  string
+		data  []byte
+		mode  uint32
+		size  int64
+		dirty bool
+		ids   []int32
+		hash  []byte
+	}
+	var (
+		buf    bytes.Buffer
+		endian = binary.LittleEndian
+		f      = file{
+			name:  "sillyprog",
+			data:  []byte{1, 2},
+			mode:  0o644,
+			size:  -2,
+			dirty: true,
+			ids:   []int32{1, -1},
+			hash:  []byte{0xAB, 0xCD, 0xEF},
+		}
+	)
+	m := MapSequence(
+		XDRString(&f.name, 255),
+		XDROpaque(&f.data, 0),
+		XDRInt(&f.mode),
+		XDRInt(&f.size),
+		XDRBool(&f.dirty),
+		XDRArray(&f.ids, 0, XDRInt[int32]),
+		XDRFixedOpaque(&f.hash, 3),
+	)
+	assert.NoError(t, m.Write(&buf, endian))
+	assert.Equal(t, []byte{
+		0, 0, 0, 9, 's', 'i', 'l', 'l', 'y', 'p', 'r', 'o', 'g', 0, 0, 0,
+		0, 0, 0, 2, 1, 2, 0, 0,
+		0, 0, 0x01, 0xA4,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFE,
+		0, 0, 0, 1,
+		0, 0, 0, 2, 0, 0, 0, 1, 0xFF, 0xFF, 0xFF, 0xFF,
+		0xAB, 0xCD, 0xEF, 0,
+	}, buf.Bytes(), "Everything should be big-endian and padded to 4 bytes")
+
+	expected := f
+	f = file{}
+	assert.NoError(t, m.Read(&buf, endian))
+	assert.Equal(t, expected, f)
+	assert.Equal(t, 0, buf.Len())
+
+	f.name = "toolong"
+	assert.ErrorIs(t, XDRString(&f.name, 4).Write(&buf, endian), ErrStringTooLong)
+	buf.Reset()
+	buf.Write([]byte{0, 0, 0, 8})
+	assert.ErrorIs(t, XDRString(&f.name, 4).Read(&buf, endian), ErrStringTooLong)
+	f.ids = []int32{1, 2, 3}
+	assert.ErrorIs(t, XDRArray(&f.ids, 2, XDRInt[int32]).Write(&buf, endian), ErrOutOfBounds)
+
+	f.data = []byte{1, 2, 3, 4}
+	buf.Reset()
+	assert.NoError(t, XDROpaque(&f.data, 0).Write(&buf, endian))
+	assert.Equal(t, 8, buf.Len(), "No padding is needed for aligned data")
+}